@@ -16,32 +16,97 @@ import (
 // robdd(-phi) = robdd(phi -> false)
 // robdd(phi # rho) = apply(robdd(phi), robdd(rho), #)
 
-func buildTree(e operators.Expression) (root operators.Node) {
+// Builder builds BDDs from expressions.
+// The result of every apply step is cached, such that a pair of sub-diagrams is combined only once per operator.
+type Builder struct {
+	cache    map[applyKey]operators.Node
+	stats    Stats
+	progress progress
+}
+
+type applyKey struct {
+	a, b operators.Node
+	op   reflect.Type
+}
+
+// NewBuilder creates a builder with an empty apply cache
+func NewBuilder() *Builder {
+	return &Builder{
+		cache: make(map[applyKey]operators.Node),
+	}
+}
+
+// Stats returns the number of created nodes and cache hits so far
+func (b *Builder) Stats() Stats {
+	return b.stats
+}
+
+// SetProgress calls fn with the statistics of the builder after every interval created nodes.
+// A nil fn disables progress reporting.
+func (b *Builder) SetProgress(interval int, fn ProgressFunc) {
+	b.progress.set(interval, fn)
+}
+
+func (b *Builder) choice(v operators.Variable, trueTree, falseTree operators.Node) operators.Node {
+	b.stats.Nodes++
+	b.progress.step(b.stats)
+	return operators.JoinByChoice(v, trueTree, falseTree)
+}
+
+func (b *Builder) buildTree(e operators.Expression) (root operators.Node) {
 	if cons, ok := e.(operators.Constant); ok {
 		// every constant remains a constant
 		return cons
 	} else if v, ok := e.(operators.Variable); ok {
 		// for every variable p: introduce choice p(true, false)
-		return operators.NewChoice(v)
+		return b.choice(v, operators.Cons(true), operators.Cons(false))
+	} else if c, ok := e.(*operators.Cardinality); ok {
+		// cardinality constraints are built directly
+		return b.cardinality(c)
+	} else if p, ok := e.(*operators.PseudoBoolean); ok {
+		// pseudo-boolean constraints are built directly
+		return b.pseudoBoolean(p)
+	} else if c, ok := e.(*operators.NConjunction); ok {
+		return b.balancedApply(c.Operands, &operators.Conjunction{}, operators.Cons(true))
+	} else if d, ok := e.(*operators.NDisjunction); ok {
+		return b.balancedApply(d.Operands, &operators.Disjunction{}, operators.Cons(false))
 	} else if _, ok := e.(*operators.Negation); ok {
 		panic("negation operator cannot exist in an expression, make sure to prune")
 		// 	special case for negations, due to a compatibility issue for CNF terms, negations need to be converted
 		//return buildTree(operators.Implies(v.Negate(), operators.Cons(false)))
 	} else if op, ok := e.(operators.Operator); ok {
 		// first make sure the subtrees are complete
-		a, b := buildTree(e.LeftChild()), buildTree(e.RightChild())
+		left, right := b.buildTree(e.LeftChild()), b.buildTree(e.RightChild())
 
 		// do an apply step on the two subtrees with the given expression e
-		return Apply(a, b, op)
+		return b.Apply(left, right, op)
 	}
 	return e
 }
 
+// PruneUnary replaces every negation ¬e by e → false.
+// Shared subexpressions are pruned once, and remain shared in the result.
 func PruneUnary(e operators.Expression) operators.Expression {
+	return pruneUnary(e, make(map[operators.Expression]operators.Expression))
+}
+
+func pruneUnary(e operators.Expression, memo map[operators.Expression]operators.Expression) operators.Expression {
 	if e == nil {
 		return nil
+	} else if pruned, ok := memo[e]; ok {
+		return pruned
 	} else if v, ok := e.(*operators.Negation); ok {
-		return operators.Implies(PruneUnary(v.T), operators.Cons(false))
+		pruned = operators.Implies(pruneUnary(v.T, memo), operators.Cons(false))
+		memo[e] = pruned
+		return pruned
+	} else if c, ok := e.(*operators.NConjunction); ok {
+		pruned = &operators.NConjunction{Operands: pruneOperands(c.Operands, memo)}
+		memo[e] = pruned
+		return pruned
+	} else if d, ok := e.(*operators.NDisjunction); ok {
+		pruned = &operators.NDisjunction{Operands: pruneOperands(d.Operands, memo)}
+		memo[e] = pruned
+		return pruned
 	} else {
 		// only prune nodes with children
 		if e.LeftChild() == nil && e.RightChild() == nil {
@@ -51,29 +116,52 @@ func PruneUnary(e operators.Expression) operators.Expression {
 		tp := reflect.ValueOf(e).Elem().Type()
 		clone := reflect.New(tp).Interface().(operators.Expression)
 
-		left := PruneUnary(e.LeftChild())
-		right := PruneUnary(e.RightChild())
+		left := pruneUnary(e.LeftChild(), memo)
+		right := pruneUnary(e.RightChild(), memo)
 
 		clone.SetLeftChild(left)
 		clone.SetRightChild(right)
 
+		memo[e] = clone
 		return clone
 	}
 }
 
+func pruneOperands(operands []operators.Expression, memo map[operators.Expression]operators.Expression) []operators.Expression {
+	result := make([]operators.Expression, len(operands))
+	for i, o := range operands {
+		result[i] = pruneUnary(o, memo)
+	}
+	return result
+}
+
+// balancedApply combines the bdds of the operands of an n-ary operator pairwise, as a balanced tree of apply steps,
+// such that intermediate diagrams stay small and the recursion depth is logarithmic in the number of operands.
+// An n-ary operator without operands is its identity element.
+func (b *Builder) balancedApply(operands []operators.Expression, op operators.Operator, identity operators.Constant) operators.Node {
+	switch len(operands) {
+	case 0:
+		return identity
+	case 1:
+		return b.buildTree(operands[0])
+	}
+	half := len(operands) / 2
+	return b.Apply(b.balancedApply(operands[:half], op, identity), b.balancedApply(operands[half:], op, identity), op)
+}
+
 // not working at the moment due to equivalence issue
-func reduceTree(root operators.Node) operators.Node {
+func (b *Builder) reduceTree(root operators.Node) operators.Node {
 	switch root.(type) {
 	case operators.Constant:
 		return root
 	case *operators.Choice:
-		left := reduceTree(root.LeftChild())
-		right := reduceTree(root.RightChild())
+		left := b.reduceTree(root.LeftChild())
+		right := b.reduceTree(root.RightChild())
 
 		if bdd.Equivalent(left, right) {
 			return left
 		} else {
-			return operators.JoinByChoice(root.(*operators.Choice).Var, left, right)
+			return b.choice(root.(*operators.Choice).Var, left, right)
 		}
 	}
 	return root
@@ -81,8 +169,13 @@ func reduceTree(root operators.Node) operators.Node {
 
 // FromExpression builds a bdd from a given expression
 func FromExpression(e operators.Expression) operators.Node {
-	root := buildTree(e)
-	return reduceTree(root)
+	return NewBuilder().FromExpression(e)
+}
+
+// FromExpression builds a bdd from a given expression, reusing the apply cache of previous calls
+func (b *Builder) FromExpression(e operators.Expression) operators.Node {
+	root := b.buildTree(e)
+	return b.reduceTree(root)
 }
 
 // apply(T, U, #) = #(T, U)
@@ -95,20 +188,33 @@ func FromExpression(e operators.Expression) operators.Node {
 // if p is on top of U but does not occur in T: #(T, p(U1, U2)) = p(#(T, U1), #(T, U2))
 
 func Apply(a, b operators.Node, op operators.Operator) operators.Node {
-	// a and b both constant: evaluate according to truth table
-	if operators.IsConstant(a) && operators.IsConstant(b) {
-		return op.ConstEval(a.(operators.Constant), b.(operators.Constant))
+	return NewBuilder().Apply(a, b, op)
+}
+
+// Apply combines two bdds with a binary operator
+func (b *Builder) Apply(x, y operators.Node, op operators.Operator) operators.Node {
+	// x and y both constant: evaluate according to truth table
+	if operators.IsConstant(x) && operators.IsConstant(y) {
+		return op.ConstEval(x.(operators.Constant), y.(operators.Constant))
+	}
+
+	key := applyKey{x, y, reflect.TypeOf(op)}
+	if result, ok := b.cache[key]; ok {
+		b.stats.CacheHits++
+		return result
 	}
 
 	// propagate operator to level below
-	v, left, right := operators.FindOperatorPropagation(a, b, op)
+	v, left, right := operators.FindOperatorPropagation(x, y, op)
 
 	// v.SwapTrue(FromExpression(left))
 	// v.SwapFalse(FromExpression(right))
 
 	// todo: use choice operator from variable v
 	// return v
-	return operators.JoinByChoice(v.Var, FromExpression(left), FromExpression(right))
+	result := b.choice(v.Var, b.FromExpression(left), b.FromExpression(right))
+	b.cache[key] = result
+	return result
 }
 
 // todo: introduce simplifications for implication, biimplication, xor, nor to CNF
@@ -0,0 +1,100 @@
+package algorithm
+
+import (
+	"context"
+
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// Backbone returns the literals over vars that are true in every model of cnf, e.g. the options that are forced or
+// forbidden in a configuration. If vars is empty, the backbone over every variable of cnf is returned.
+// If cnf is unsatisfiable, sat is false and the backbone is nil.
+func Backbone(cnf operators.CNF, vars []operators.Variable) (backbone []operators.Term, sat bool) {
+	s := NewSolver()
+	s.AddCNF(cnf)
+
+	backbone, status := s.Backbone(vars)
+	return backbone, status == Satisfiable
+}
+
+// Backbone returns the literals over vars that are true in every model of the clauses in the solver under the given
+// assumptions. If vars is empty, the backbone over every variable of the solver is returned.
+// The backbone is computed by iterative SAT calls: the first model gives a candidate literal for every variable, and
+// every candidate is tested by solving under its negation. A candidate is part of the backbone iff this is
+// unsatisfiable; otherwise, the new model removes every candidate that it falsifies.
+// The status is Unsatisfiable if there is no model under the assumptions, and Unknown if the budget of the solver is
+// exhausted. In both cases the backbone is nil.
+func (s *Solver) Backbone(vars []operators.Variable, assumptions ...operators.Term) ([]operators.Term, Status) {
+	ctx := context.Background()
+
+	if status := s.SolveContext(ctx, assumptions...); status != Satisfiable {
+		return nil, status
+	}
+
+	type candidate struct {
+		term operators.Term
+		lit  literal
+	}
+
+	// the candidates are the literals of the first model
+	var candidates []candidate
+	seen := make(map[int]bool)
+	add := func(v operators.Variable) {
+		lit, _ := s.literal(v)
+		if seen[lit.variable()] {
+			return
+		}
+		seen[lit.variable()] = true
+
+		if s.modelValue(v) {
+			candidates = append(candidates, candidate{v, lit})
+		} else {
+			candidates = append(candidates, candidate{v.Negate(), lit.negate()})
+		}
+	}
+
+	if len(vars) > 0 {
+		for _, v := range vars {
+			add(v)
+		}
+	} else {
+		for _, v := range s.variables {
+			if v != nil {
+				add(v)
+			}
+		}
+	}
+
+	var backbone []operators.Term
+	tested := append([]operators.Term(nil), assumptions...)
+
+	for i, c := range candidates {
+		if c.term == nil {
+			// falsified by a previous model
+			continue
+		}
+
+		status := s.SolveContext(ctx, append(tested, c.term.Negate())...)
+
+		switch status {
+		case Unknown:
+			return nil, Unknown
+		case Unsatisfiable:
+			backbone = append(backbone, c.term)
+			if len(assumptions) == 0 {
+				s.AddClause(operators.NClause{c.term})
+			} else {
+				// backbone literals only hold under the assumptions, so they are assumed instead of added
+				tested = append(tested, c.term)
+			}
+		case Satisfiable:
+			for j := i + 1; j < len(candidates); j++ {
+				if next := &candidates[j]; next.term != nil && s.model[s.variables[next.lit.variable()]] == next.lit.negative() {
+					next.term = nil
+				}
+			}
+		}
+	}
+
+	return backbone, Satisfiable
+}
@@ -0,0 +1,86 @@
+package algorithm
+
+import (
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+func TestBackbone(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	cnf := readDIMACS(t, "testdata/uf20-91.cnf")
+
+	// the backbone is the intersection of all models
+	var vars []operators.Variable
+	for _, v := range operators.Variables(cnf) {
+		vars = append(vars, v.Variable())
+	}
+
+	always := make(map[operators.Variable]bool)
+	never := make(map[operators.Variable]bool)
+	for _, v := range vars {
+		always[v], never[v] = true, true
+	}
+	EnumerateModels(cnf, vars, func(model operators.Model) bool {
+		for _, v := range vars {
+			always[v] = always[v] && model[v]
+			never[v] = never[v] && !model[v]
+		}
+		return true
+	})
+
+	expected := 0
+	for _, v := range vars {
+		if always[v] || never[v] {
+			expected++
+		}
+	}
+
+	backbone, sat := Backbone(cnf, nil)
+	be.Assert("uf20-91 is satisfiable", sat)
+	be.AssertInfo("backbone has the size of the intersection of all models", len(backbone) == expected, backbone, expected)
+
+	for _, lit := range backbone {
+		v := lit.Variable()
+		if _, negative := lit.(*operators.Negation); negative {
+			be.AssertInfo("negative backbone literal is false in every model", never[v], lit)
+		} else {
+			be.AssertInfo("positive backbone literal is true in every model", always[v], lit)
+		}
+	}
+
+	backbone, sat = Backbone(cnf, vars[:5])
+	be.AssertInfo("backbone is restricted to the given variables", sat && len(backbone) <= 5, backbone)
+
+	backbone, sat = Backbone(pigeonhole(3), nil)
+	be.AssertInfo("unsat cnf has no backbone", !sat && backbone == nil, backbone)
+}
+
+func TestSolverBackbone(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c, d := operators.Var("a"), operators.Var("b"), operators.Var("c"), operators.Var("d")
+
+	// a requires b, and b excludes c
+	s := NewSolver()
+	s.AddCNF(operators.CNF{
+		operators.NClause{a.Negate(), b},
+		operators.NClause{b.Negate(), c.Negate()},
+	})
+
+	vars := []operators.Variable{a, b, c, d}
+
+	backbone, status := s.Backbone(vars)
+	be.AssertInfo("no option is forced without choices", status == Satisfiable && len(backbone) == 0, backbone)
+
+	backbone, status = s.Backbone(vars, a)
+	be.AssertInfo("choosing a forces a and b, and forbids c", status == Satisfiable && len(backbone) == 3, backbone)
+	be.AssertInfo("backbone is ordered by vars", len(backbone) == 3 &&
+		backbone[0] == operators.Term(a) && backbone[1] == operators.Term(b) && backbone[2].Variable() == c, backbone)
+
+	backbone, status = s.Backbone(vars, a, c)
+	be.AssertInfo("conflicting choices have no backbone", status == Unsatisfiable && backbone == nil, backbone)
+
+	be.Assert("backbone under assumptions does not constrain the solver", s.Solve(c))
+}
@@ -0,0 +1,463 @@
+package algorithm
+
+import (
+	"sort"
+
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// CardinalityEncoding selects the CNF encoding of a cardinality constraint
+type CardinalityEncoding int
+
+const (
+	// SequentialCounter encodes the constraint by a chain of unary counters (Sinz, 2005)
+	SequentialCounter CardinalityEncoding = iota
+	// Totalizer encodes the constraint by a tree of unary adders (Bailleux and Boufkhad, 2003)
+	Totalizer
+	// CardinalityNetwork encodes the constraint by an odd-even merge sorting network,
+	// of which only the comparators that determine the first outputs are encoded (Asín et al., 2009)
+	CardinalityNetwork
+	// Commander encodes the constraint by groups of terms, every group is represented by commander variables,
+	// on which the constraint is encoded recursively (Klieber and Kwon, 2007)
+	Commander
+)
+
+// EncodeCardinality returns clauses that are satisfiable iff the cardinality constraint holds.
+// The auxiliary variables of the encoding are fresh variables.
+// Every encoding encodes at-most constraints, at-least constraints are encoded as at-most constraints on the negated terms.
+func EncodeCardinality(c *operators.Cardinality, encoding CardinalityEncoding) operators.CNF {
+	terms, min, max := cardinalityTerms(c)
+
+	if min > max || max < 0 || min > len(terms) {
+		return operators.CNF{operators.NClause{}}
+	}
+
+	result := make(operators.CNF, 0)
+
+	if max < len(terms) {
+		result = append(result, encodeAtMost(terms, max, encoding)...)
+	}
+
+	if min > 0 {
+		negated := make([]operators.Term, len(terms))
+		for i, t := range terms {
+			negated[i] = t.Negate()
+		}
+		result = append(result, encodeAtMost(negated, len(terms)-min, encoding)...)
+	}
+
+	return result
+}
+
+// cardinalityTerms removes the constant terms from the constraint, and adjusts the bounds accordingly
+func cardinalityTerms(c *operators.Cardinality) (terms []operators.Term, min, max int) {
+	terms = make([]operators.Term, 0, len(c.Terms))
+	min, max = c.Min, c.Max
+
+	for _, t := range c.Terms {
+		if constant, ok := t.(operators.Constant); ok {
+			if constant.Value() {
+				min--
+				max--
+			}
+			continue
+		}
+		terms = append(terms, t)
+	}
+
+	if min < 0 {
+		min = 0
+	}
+
+	return
+}
+
+func encodeAtMost(terms []operators.Term, k int, encoding CardinalityEncoding) operators.CNF {
+	if k == 0 {
+		result := make(operators.CNF, len(terms))
+		for i, t := range terms {
+			result[i] = t.Negate()
+		}
+		return result
+	}
+
+	switch encoding {
+	case SequentialCounter:
+		return sequentialCounter(terms, k)
+	case Totalizer:
+		outputs, result := totalizer(terms, k+1, true, false)
+		return append(result, outputs[k].Negate())
+	case CardinalityNetwork:
+		return cardinalityNetwork(terms, k)
+	case Commander:
+		return commander(terms, k)
+	default:
+		panic("unrecognized cardinality encoding")
+	}
+}
+
+// sequentialCounter encodes that at most k of the terms are true, where 0 < k < len(terms).
+// Register s[i][j] is true if at least j+1 of the first i+1 terms are true.
+func sequentialCounter(terms []operators.Term, k int) operators.CNF {
+	n := len(terms)
+	result := make(operators.CNF, 0, 2*n*k+n)
+
+	s := make([][]operators.Term, n-1)
+	for i := range s {
+		s[i] = operators.IncVarCollection(k)
+	}
+
+	result = append(result, operators.NClause{terms[0].Negate(), s[0][0]})
+	for j := 1; j < k; j++ {
+		result = append(result, s[0][j].Negate())
+	}
+
+	for i := 1; i < n-1; i++ {
+		result = append(result,
+			operators.NClause{terms[i].Negate(), s[i][0]},
+			operators.NClause{s[i-1][0].Negate(), s[i][0]},
+		)
+		for j := 1; j < k; j++ {
+			result = append(result,
+				operators.NClause{terms[i].Negate(), s[i-1][j-1].Negate(), s[i][j]},
+				operators.NClause{s[i-1][j].Negate(), s[i][j]},
+			)
+		}
+		result = append(result, operators.NClause{terms[i].Negate(), s[i-1][k-1].Negate()})
+	}
+
+	return append(result, operators.NClause{terms[n-1].Negate(), s[n-2][k-1].Negate()})
+}
+
+// totalizer returns the outputs of a totalizer over the terms, where output j is true iff at least j+1 terms are true.
+// Only the first m outputs are encoded, the last output is true iff at least m terms are true.
+// The upward clauses make the outputs true if the terms are, the downward clauses make the outputs false otherwise.
+func totalizer(terms []operators.Term, m int, upward, downward bool) (outputs []operators.Term, cnf operators.CNF) {
+	if len(terms) == 1 {
+		return terms, nil
+	}
+
+	left, leftCNF := totalizer(terms[:len(terms)/2], m, upward, downward)
+	right, rightCNF := totalizer(terms[len(terms)/2:], m, upward, downward)
+
+	cnf = append(leftCNF, rightCNF...)
+
+	size := len(left) + len(right)
+	if size > m {
+		size = m
+	}
+	outputs = operators.IncVarCollection(size)
+
+	for i := 0; i <= len(left); i++ {
+		for j := 0; j <= len(right); j++ {
+			sum := i + j
+
+			if upward && sum > 0 {
+				clause := make(operators.NClause, 0, 3)
+				if i > 0 {
+					clause = append(clause, left[i-1].Negate())
+				}
+				if j > 0 {
+					clause = append(clause, right[j-1].Negate())
+				}
+				if sum > size {
+					sum = size
+				}
+				cnf = append(cnf, append(clause, outputs[sum-1]))
+			}
+
+			if downward && sum < size {
+				clause := make(operators.NClause, 0, 3)
+				if i < len(left) {
+					clause = append(clause, left[i])
+				}
+				if j < len(right) {
+					clause = append(clause, right[j])
+				}
+				cnf = append(cnf, append(clause, outputs[sum].Negate()))
+			}
+		}
+	}
+
+	return outputs, cnf
+}
+
+// cardinalityNetwork encodes that at most k of the terms are true, where 0 < k < len(terms).
+// The terms are sorted in descending order by an odd-even merge sort, after which output k must be false.
+// Comparators only imply their outputs (upward clauses), and comparators that do not determine one of the first
+// k+1 outputs are not encoded.
+func cardinalityNetwork(terms []operators.Term, k int) operators.CNF {
+	n := 1
+	for n < len(terms) {
+		n *= 2
+	}
+
+	// the comparators of the odd-even merge sort on n wires
+	var comparators [][2]int
+	for p := 1; p < n; p *= 2 {
+		for q := p; q > 0; q /= 2 {
+			for j := q % p; j+q < n; j += 2 * q {
+				for i := 0; i < q && i+j+q < n; i++ {
+					if (i+j)/(2*p) == (i+j+q)/(2*p) {
+						comparators = append(comparators, [2]int{i + j, i + j + q})
+					}
+				}
+			}
+		}
+	}
+
+	// determine the comparators that contribute to the first k+1 outputs
+	needed := make([]bool, n)
+	for i := 0; i <= k; i++ {
+		needed[i] = true
+	}
+	used := make([]bool, len(comparators))
+	for c := len(comparators) - 1; c >= 0; c-- {
+		a, b := comparators[c][0], comparators[c][1]
+		if needed[a] || needed[b] {
+			used[c] = true
+			needed[a], needed[b] = true, true
+		}
+	}
+
+	wires := make([]operators.Term, n)
+	for i := range wires {
+		if i < len(terms) {
+			wires[i] = terms[i]
+		} else {
+			wires[i] = operators.Cons(false)
+		}
+	}
+
+	result := make(operators.CNF, 0)
+
+	for c, comparator := range comparators {
+		if !used[c] {
+			continue
+		}
+
+		a, b := wires[comparator[0]], wires[comparator[1]]
+
+		// a comparator with a false input passes the other input
+		if operators.IsConstant(b) {
+			continue
+		}
+		if operators.IsConstant(a) {
+			wires[comparator[0]], wires[comparator[1]] = b, a
+			continue
+		}
+
+		high, low := operators.IncVar(), operators.IncVar()
+		result = append(result,
+			operators.NClause{a.Negate(), high},
+			operators.NClause{b.Negate(), high},
+			operators.NClause{a.Negate(), b.Negate(), low},
+		)
+		wires[comparator[0]], wires[comparator[1]] = high, low
+	}
+
+	return append(result, wires[k].Negate())
+}
+
+// commander encodes that at most k of the terms are true, where 0 < k < len(terms).
+// The terms are split into groups of k+2 terms, every group has at most k commanders, and at least as many
+// commanders as terms are true in the group. At most k commanders are true, which is encoded recursively.
+func commander(terms []operators.Term, k int) operators.CNF {
+	size := k + 2
+
+	if len(terms) <= k {
+		return nil
+	}
+
+	if len(terms) <= size {
+		if k == 1 {
+			return pairwiseAtMostOne(terms)
+		}
+		outputs, result := totalizer(terms, k+1, true, false)
+		return append(result, outputs[k].Negate())
+	}
+
+	result := make(operators.CNF, 0)
+	commanders := make([]operators.Term, 0, len(terms))
+
+	for start := 0; start < len(terms); start += size {
+		end := start + size
+		if end > len(terms) {
+			end = len(terms)
+		}
+		group := terms[start:end]
+
+		if len(group) <= k {
+			// small groups are their own commanders
+			commanders = append(commanders, group...)
+			continue
+		}
+
+		c := operators.IncVarCollection(k)
+		commanders = append(commanders, c...)
+
+		if k == 1 {
+			result = append(result, pairwiseAtMostOne(group)...)
+			for _, t := range group {
+				result = append(result, operators.NClause{t.Negate(), c[0]})
+			}
+			continue
+		}
+
+		outputs, cnf := totalizer(group, k+1, true, false)
+		result = append(result, cnf...)
+		result = append(result, outputs[k].Negate())
+		for j := 0; j < k; j++ {
+			result = append(result, operators.NClause{outputs[j].Negate(), c[j]})
+			if j > 0 {
+				// symmetry breaking: the commanders of a group are true in order
+				result = append(result, operators.NClause{c[j].Negate(), c[j-1]})
+			}
+		}
+	}
+
+	return append(result, commander(commanders, k)...)
+}
+
+func pairwiseAtMostOne(terms []operators.Term) operators.CNF {
+	result := make(operators.CNF, 0, len(terms)*(len(terms)-1)/2)
+	for i := range terms {
+		for j := i + 1; j < len(terms); j++ {
+			result = append(result, operators.NClause{terms[i].Negate(), terms[j].Negate()})
+		}
+	}
+	return result
+}
+
+// reifyCardinality returns clauses that make v equivalent to the cardinality constraint, as used by the Tseitin
+// transformation. The number of true terms is counted by a totalizer with both upward and downward clauses.
+func reifyCardinality(v operators.Term, c *operators.Cardinality) operators.CNF {
+	terms, min, max := cardinalityTerms(c)
+
+	if min > max || max < 0 || min > len(terms) {
+		return operators.CNF{v.Negate()}
+	}
+	if min == 0 && max >= len(terms) {
+		return operators.CNF{v}
+	}
+
+	m := len(terms)
+	if max < m {
+		m = max + 1
+	}
+	outputs, result := totalizer(terms, m, true, true)
+
+	// v is true iff output min-1 is true (at least min) and output max is false (at most max)
+	clause := operators.NClause{v}
+	if min > 0 {
+		result = append(result, operators.NClause{v.Negate(), outputs[min-1]})
+		clause = append(clause, outputs[min-1].Negate())
+	}
+	if max < len(terms) {
+		result = append(result, operators.NClause{v.Negate(), outputs[max].Negate()})
+		clause = append(clause, outputs[max])
+	}
+
+	return append(result, clause)
+}
+
+// cardinality builds the bdd of a cardinality constraint directly
+func (b *Builder) cardinality(c *operators.Cardinality) operators.Node {
+	terms, min, max := cardinalityTerms(c)
+
+	weights := make([]int, len(terms))
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	return b.linear(terms, weights, min, max)
+}
+
+// linear builds the bdd of the constraint min ≤ Σ weights[i]·terms[i] ≤ max, where every weight is positive,
+// by summing the weights of the true terms along the variable order
+func (b *Builder) linear(terms []operators.Term, weights []int, min, max int) operators.Node {
+	// the sum of the weights of the terms of every variable that are true if the variable is true or false
+	type count struct {
+		v         operators.Variable
+		high, low int
+		remaining int
+	}
+
+	index := make(map[interface{}]int)
+	var counts []count
+	for j, t := range terms {
+		v := t.Variable()
+		key := operators.VariableKey(v)
+		i, ok := index[key]
+		if !ok {
+			i = len(counts)
+			index[key] = i
+			counts = append(counts, count{v: v})
+		}
+		if _, negative := t.(*operators.Negation); negative {
+			counts[i].low += weights[j]
+		} else {
+			counts[i].high += weights[j]
+		}
+	}
+
+	sort.SliceStable(counts, func(i, j int) bool {
+		return !counts[j].v.Leq(counts[i].v)
+	})
+
+	// remaining is the maximum sum of the weights of the terms that can still become true
+	for i := len(counts) - 1; i >= 0; i-- {
+		counts[i].remaining = counts[i].high
+		if counts[i].low > counts[i].remaining {
+			counts[i].remaining = counts[i].low
+		}
+		if i+1 < len(counts) {
+			counts[i].remaining += counts[i+1].remaining
+		}
+	}
+
+	type state struct{ i, sum int }
+	type unique struct {
+		i         int
+		high, low operators.Node
+	}
+
+	memo := make(map[state]operators.Node)
+	nodes := make(map[unique]operators.Node)
+
+	var build func(i, sum int) operators.Node
+	build = func(i, sum int) operators.Node {
+		if sum > max {
+			return operators.Cons(false)
+		}
+		if i == len(counts) || (sum >= min && sum+counts[i].remaining <= max) {
+			return operators.Cons(sum >= min)
+		}
+		if sum+counts[i].remaining < min {
+			return operators.Cons(false)
+		}
+
+		if n, ok := memo[state{i, sum}]; ok {
+			return n
+		}
+
+		high := build(i+1, sum+counts[i].high)
+		low := build(i+1, sum+counts[i].low)
+
+		n := high
+		if high != low {
+			key := unique{i, high, low}
+			if existing, ok := nodes[key]; ok {
+				n = existing
+			} else {
+				n = b.choice(counts[i].v, high, low)
+				nodes[key] = n
+			}
+		}
+
+		memo[state{i, sum}] = n
+		return n
+	}
+
+	return build(0, 0)
+}
@@ -0,0 +1,130 @@
+package algorithm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// binomialRange returns the number of assignments of n variables where at least min and at most max are true
+func binomialRange(n, min, max int) int64 {
+	var total, binomial int64 = 0, 1
+	for k := 0; k <= n; k++ {
+		if k >= min && k <= max {
+			total += binomial
+		}
+		binomial = binomial * int64(n-k) / int64(k+1)
+	}
+	return total
+}
+
+func TestEncodeCardinality(t *testing.T) {
+	const n = 7
+
+	vars := make([]operators.Variable, n)
+	terms := make([]operators.Term, n)
+	for i := range vars {
+		vars[i] = operators.Var(fmt.Sprintf("x_%d", i))
+		terms[i] = vars[i]
+	}
+
+	encodings := map[string]CardinalityEncoding{
+		"sequential counter":  SequentialCounter,
+		"totalizer":           Totalizer,
+		"cardinality network": CardinalityNetwork,
+		"commander":           Commander,
+	}
+
+	for name, encoding := range encodings {
+		be := bdd_test.Bench{T: t}
+
+		for k := 0; k <= n+1; k++ {
+			constraints := map[string]*operators.Cardinality{
+				"at most":  operators.AtMost(k, terms...),
+				"at least": operators.AtLeast(k, terms...),
+				"exactly":  operators.Exactly(k, terms...),
+			}
+			expected := map[string]int64{
+				"at most":  binomialRange(n, 0, k),
+				"at least": binomialRange(n, k, n),
+				"exactly":  binomialRange(n, k, k),
+			}
+
+			for kind, c := range constraints {
+				count := CountModels(EncodeCardinality(c, encoding), vars)
+				be.AssertInfo(fmt.Sprintf("%s encodes %s %d of %d", name, kind, k, n), count.Int64() == expected[kind], count, expected[kind])
+			}
+		}
+	}
+}
+
+func TestEncodeCardinalityTerms(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c, d := operators.Var("a"), operators.Var("b"), operators.Var("c"), operators.Var("d")
+	vars := []operators.Variable{a, b, c, d}
+
+	// exactly two of a, not b, c, d and true: exactly one of a, not b, c, d
+	constraint := operators.Exactly(2, a, b.Negate(), c, d, operators.Cons(true))
+
+	for _, encoding := range []CardinalityEncoding{SequentialCounter, Totalizer, CardinalityNetwork, Commander} {
+		cnf := EncodeCardinality(constraint, encoding)
+		be.AssertInfo("constant terms are counted", CountModels(cnf, vars).Int64() == 4, encoding)
+
+		model := CDCL(append(cnf, b, a)).Model
+		be.AssertInfo("negated terms are counted", model[b] && model[a] && !model[c] && !model[d], encoding, model)
+	}
+
+	cnf := EncodeCardinality(operators.AtLeast(3, a, b), Totalizer)
+	be.Assert("at least 3 of 2 terms is unsat", !CDCL(cnf).Sat())
+}
+
+func TestCardinalityBDD(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c, d := operators.Var("a"), operators.Var("b"), operators.Var("c"), operators.Var("d")
+
+	direct := FromExpression(operators.And(operators.Or(a, d), operators.Exactly(1, d, c, b, a)))
+
+	pairwise := operators.And(
+		operators.Or(a, d),
+		operators.Or(a, b, c, d),
+		operators.Not(operators.And(a, b)),
+		operators.Not(operators.And(a, c)),
+		operators.Not(operators.And(a, d)),
+		operators.Not(operators.And(b, c)),
+		operators.Not(operators.And(b, d)),
+		operators.Not(operators.And(c, d)),
+	)
+
+	be.AssertEquivalent("direct bdd is equivalent to the pairwise encoding", direct, FromExpression(PruneUnary(pairwise)))
+
+	be.AssertSat("at most 2 of 4 is satisfiable", FromExpression(operators.AtMost(2, a, b, c, d)))
+	be.AssertUnsat("at least 2 of a and not a is unsatisfiable", FromExpression(operators.AtLeast(2, a, a.Negate())))
+	be.AssertTautology("exactly 1 of a and not a is a tautology", FromExpression(operators.Exactly(1, a, a.Negate())))
+}
+
+func TestCardinalityTseitin(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c, d := operators.Var("a"), operators.Var("b"), operators.Var("c"), operators.Var("d")
+	vars := []operators.Variable{a, b, c, d}
+
+	expressions := []struct {
+		name   string
+		expr   operators.Expression
+		models int64
+	}{
+		{"not exactly 2", operators.Not(operators.Exactly(2, a, b, c, d)), 16 - 6},
+		{"at most 1 or a", operators.Or(operators.AtMost(1, a, b, c, d), a), 5 + 8 - 1},
+		{"a xor at least 3", operators.Xor(a, operators.AtLeast(3, a, b, c, d)), 4 + 1},
+		{"at least 2 implies d", operators.Implies(operators.AtLeast(2, a, b, c, d), d), 5 + 8 - 1},
+		{"exactly 2 and not d", operators.And(operators.Exactly(2, a, b, c, d), operators.Not(d)), 3},
+		{"not at most 4 of 4", operators.Not(operators.AtMost(4, a, b, c, d)), 0},
+		{"not at least 0 or true", operators.Or(operators.Not(operators.AtLeast(0, a, b, c, d)), operators.Cons(true)), 16},
+	}
+
+	for _, e := range expressions {
+		count := CountModels(TransformTseitin(NNF(e.expr)), vars)
+		be.AssertInfo(fmt.Sprintf("tseitin transformation of %s has %d models", e.name, e.models), count.Int64() == e.models, count)
+	}
+}
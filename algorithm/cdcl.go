@@ -1,6 +1,9 @@
 package algorithm
 
 import (
+	"context"
+	"io"
+
 	"github.com/timbeurskens/gobdd/operators"
 )
 
@@ -13,6 +16,8 @@ import (
 type CDCLStack struct {
 	Clauses operators.CNF
 	Indexes []int
+
+	stats Stats
 }
 
 func NewCDCLStack(cnf operators.CNF) *CDCLStack {
@@ -36,12 +41,12 @@ func (s *CDCLStack) Decide(term operators.Term) {
 	position := len(s.Clauses)
 	s.Clauses = append(s.Clauses, term)
 	s.Indexes = append(s.Indexes, position)
-	//log.Println("decide", term, *s)
+	s.stats.Decisions++
 }
 
 func (s *CDCLStack) UnitPropagate(clause operators.CNFClause) {
 	s.Clauses = append(s.Clauses, clause)
-	//log.Println("propagate", clause, *s)
+	s.stats.Propagations++
 }
 
 func (s *CDCLStack) Backtrack() operators.Term {
@@ -49,10 +54,14 @@ func (s *CDCLStack) Backtrack() operators.Term {
 	s.Indexes, last = s.Indexes[:len(s.Indexes)-1], s.Indexes[len(s.Indexes)-1]
 	term := s.Clauses[last].(operators.Term)
 	s.Clauses = s.Clauses[:last]
-	//log.Println("backtrack", *s)
 	return term
 }
 
+// Stats returns the number of decisions, propagations and conflicts on the stack so far
+func (s *CDCLStack) Stats() Stats {
+	return s.stats
+}
+
 func (s *CDCLStack) IsTrue(term operators.Term) bool {
 	for _, clause := range s.Clauses {
 		if clause.NumTerms() == 1 && clause.Exclude(term) == nil {
@@ -62,43 +71,130 @@ func (s *CDCLStack) IsTrue(term operators.Term) bool {
 	return false
 }
 
-func ModelFromCDCLStack(stack *CDCLStack, variables []operators.Term) (model operators.Node) {
-	if len(variables) == 0 {
-		return &operators.TrueConst
+// ModelFromCDCLStack collects the unit clauses on the stack in a model of the variables.
+// Variables that are not determined by a unit clause are false.
+func ModelFromCDCLStack(stack *CDCLStack, variables []operators.Term) operators.Model {
+	model := make(operators.Model, len(variables))
+	canonical := make(map[interface{}]operators.Variable, len(variables))
+
+	for _, v := range variables {
+		model[v.Variable()] = false
+		canonical[operators.VariableKey(v.Variable())] = v.Variable()
 	}
 
-	choiceVar, remaining := variables[0].Variable(), variables[1:]
+	for _, clause := range stack.Clauses {
+		if clause.NumTerms() != 1 {
+			continue
+		}
 
-	var trueTree, falseTree operators.Node
+		term := clause.Terms()[0]
+		if _, ok := term.(operators.Constant); ok {
+			continue
+		}
 
-	if stack.IsTrue(choiceVar) && !stack.IsTrue(choiceVar.Negate()) {
-		trueTree = ModelFromCDCLStack(stack, remaining)
-		falseTree = &operators.FalseConst
-	} else if stack.IsTrue(choiceVar.Negate()) && !stack.IsTrue(choiceVar) {
-		trueTree = &operators.FalseConst
-		falseTree = ModelFromCDCLStack(stack, remaining)
-	} else {
-		return &operators.FalseConst
+		if v, ok := canonical[operators.VariableKey(term.Variable())]; ok {
+			_, negative := term.(*operators.Negation)
+			model[v] = !negative
+		}
 	}
 
-	return &operators.Choice{
-		True:  trueTree,
-		Var:   choiceVar,
-		False: falseTree,
-	}
+	return model
+}
+
+// CDCL implements the conflict-driven-clause-learning algorithm.
+// The search is an iterative loop over a trail of assignments with decision levels, see Solver.
+func CDCL(cnf operators.CNF) Result {
+	return CDCLContext(context.Background(), cnf, Options{})
 }
 
-// CDCL implements the conflict-driven-clause-learning algorithm
-func CDCL(cnf operators.CNF) (sat bool, model operators.Node) {
+// RecursiveCDCL decides one variable per level of recursion, using a CDCLStack.
+//
+// Deprecated: the recursion depth grows with the number of variables, and the search can neither backjump nor
+// restart. Use CDCL instead, RecursiveCDCL is only kept as a baseline for benchmarks.
+func RecursiveCDCL(cnf operators.CNF) Result {
+	for _, clause := range cnf {
+		if clause.NumTerms() == 0 {
+			// the empty clause can never be satisfied
+			return Result{Status: Unsatisfiable}
+		}
+	}
+
 	stack := NewCDCLStack(cnf)
 
 	variables := operators.Variables(cnf)
 
-	sat = recursiveCDCL(nil, variables, stack)
+	if !recursiveCDCL(nil, variables, stack) {
+		return Result{Status: Unsatisfiable, Stats: stack.Stats()}
+	}
 
-	model = ModelFromCDCLStack(stack, variables)
+	return Result{
+		Status: Satisfiable,
+		Model:  ModelFromCDCLStack(stack, variables),
+		Stats:  stack.Stats(),
+	}
+}
+
+// Options bounds and monitors the search of CDCLContext
+type Options struct {
+	// ConflictBudget is the maximum number of conflicts, zero means unlimited
+	ConflictBudget int
+	// PropagationBudget is the maximum number of propagated literals, zero means unlimited
+	PropagationBudget int
+	// Progress is called every ProgressInterval conflicts if it is not nil
+	Progress         ProgressFunc
+	ProgressInterval int
+	// Seed and RandomFrequency diversify the search if either is not zero, see Solver.SetRandom
+	Seed            int64
+	RandomFrequency float64
+	// Preprocess simplifies the cnf with Preprocess before the search, the model is extended to the original variables.
+	// It is ignored if Proof is set, since the proof has to refer to the original clauses.
+	Preprocess bool
+	// Proof receives a DRAT proof in ProofFormat if it is not nil, see Solver.SetProof.
+	// The proof can be verified against the DIMACS file written by operators.WriteDIMACS for the same cnf.
+	Proof       io.Writer
+	ProofFormat ProofFormat
+}
+
+// CDCLContext solves cnf with the incremental Solver, until ctx is done or a budget in opts is exhausted.
+// The status of the result is Unknown if the search was stopped before satisfiability was determined.
+func CDCLContext(ctx context.Context, cnf operators.CNF, opts Options) Result {
+	var pre *Preprocessed
+	if opts.Preprocess && opts.Proof == nil {
+		pre = Preprocess(cnf)
+		cnf = pre.CNF
+	}
+
+	s := newSolverWithOptions(opts, cnf)
+	s.AddCNF(cnf)
+
+	status := s.SolveContext(ctx)
 
-	return
+	model := s.Model()
+	if pre != nil && status == Satisfiable {
+		model = pre.Extend(model)
+	}
+
+	return Result{
+		Status:   status,
+		Model:    model,
+		Stats:    s.Stats(),
+		ProofErr: s.ProofErr(),
+	}
+}
+
+// newSolverWithOptions creates an empty solver with the budgets, progress reporting, randomization and proof of opts.
+// The proof numbers the variables of cnf as operators.WriteDIMACS does.
+func newSolverWithOptions(opts Options, cnf operators.CNF) *Solver {
+	s := NewSolver()
+	if opts.Proof != nil {
+		s.SetProof(opts.Proof, opts.ProofFormat, operators.NewNumbering(cnf))
+	}
+	s.SetBudget(opts.ConflictBudget, opts.PropagationBudget)
+	s.SetProgress(opts.ProgressInterval, opts.Progress)
+	if opts.Seed != 0 || opts.RandomFrequency != 0 {
+		s.SetRandom(opts.Seed, opts.RandomFrequency)
+	}
+	return s
 }
 
 func recursiveCDCL(v operators.Term, variables []operators.Term, stack *CDCLStack) bool {
@@ -111,6 +207,7 @@ func recursiveCDCL(v operators.Term, variables []operators.Term, stack *CDCLStac
 			if clause.HasTerm(neg) {
 				if excl := clause.Exclude(neg); excl == nil {
 					// negation!
+					stack.stats.Conflicts++
 					return false
 				} else {
 					stack.UnitPropagate(excl)
@@ -1,24 +1,29 @@
 package algorithm
 
 import (
+	"context"
 	"github.com/timbeurskens/gobdd/bdd_test"
 	"github.com/timbeurskens/gobdd/operators"
-	bdd2 "github.com/timbeurskens/gobdd/operators/bdd"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestSat(t *testing.T) {
 	be := bdd_test.Bench{T: t}
 	a, b, c := operators.Var("a"), operators.Var("b"), operators.Var("c")
-	sat, _ := CDCL(operators.CNF{operators.NClause{a, b.Negate()}, operators.NClause{c, a}})
-	be.Assert("a or not b and c or a is sat", sat)
+	result := CDCL(operators.CNF{operators.NClause{a, b.Negate()}, operators.NClause{c, a}})
+	be.Assert("a or not b and c or a is sat", result.Sat())
+	be.AssertInfo("model satisfies the clauses", result.Model[a] || (!result.Model[b] && result.Model[c]), result.Model)
 }
 
 func TestUnsat(t *testing.T) {
 	b := bdd_test.Bench{T: t}
 	a := operators.Var("a")
-	sat, _ := CDCL(operators.CNF{operators.NClause{a}, operators.NClause{a.Negate()}})
-	b.Assert("a and not a is unsat", !sat)
+	result := CDCL(operators.CNF{operators.NClause{a}, operators.NClause{a.Negate()}})
+	b.Assert("a and not a is unsat", result.Status == Unsatisfiable)
+	b.Assert("unsat result has no model", result.Model == nil)
 }
 
 func TestCNFXorSat(t *testing.T) {
@@ -26,12 +31,13 @@ func TestCNFXorSat(t *testing.T) {
 	a := operators.Var("a")
 	b := operators.Var("b")
 
-	sat, _ := CDCL(operators.CNF{
+	result := CDCL(operators.CNF{
 		operators.NClause{a.Negate(), b.Negate()},
 		operators.NClause{a, b},
 	})
 
-	be.Assert("a xor b is sat", sat)
+	be.Assert("a xor b is sat", result.Sat())
+	be.AssertInfo("model satisfies a xor b", result.Model[a] != result.Model[b], result.Model)
 }
 
 func TestCNFSat(t *testing.T) {
@@ -40,24 +46,24 @@ func TestCNFSat(t *testing.T) {
 	b := operators.Var("b")
 	c := operators.Var("c")
 
-	sat, _ := CDCL(operators.CNF{
+	result := CDCL(operators.CNF{
 		operators.NClause{a.Negate(), b.Negate(), c.Negate()},
 		operators.NClause{a, b, c},
 	})
 
-	be.Assert("a, b, c is sat", sat)
+	be.Assert("a, b, c is sat", result.Sat())
 }
 
 func TestCNFXorUnsat(t *testing.T) {
 	be := bdd_test.Bench{T: t}
 	a := operators.Var("a")
 
-	sat, _ := CDCL(operators.CNF{
+	result := CDCL(operators.CNF{
 		operators.NClause{a.Negate(), a.Negate()},
 		operators.NClause{a, a},
 	})
 
-	be.Assert("a xor a is unsat", !sat)
+	be.Assert("a xor a is unsat", !result.Sat())
 }
 
 func testCDCLTseitin(t *testing.T) {
@@ -65,23 +71,120 @@ func testCDCLTseitin(t *testing.T) {
 
 	p, q, r, s := operators.Var("p"), operators.Var("q"), operators.Var("r"), operators.Var("s")
 
-	// this example shows potential improvements to the tool: sharing for term s is possible
 	expr := operators.And(operators.Biimplies(s, q), operators.Or(r, operators.Not(p)))
 
-	// convert to NNF
-	nnf := NNF(expr)
+	cnf := TransformTseitin(expr)
 
-	cnf := TransformTseitin(nnf)
+	result := CDCL(cnf)
 
-	sat, m := CDCL(cnf)
+	be.Assert("(s <-> q) && (r || -p)", result.Sat())
 
-	be.Assert("(s <-> q) && (r || -p)", sat)
+	model := result.Model
+	be.AssertInfo("model satisfies the expression", model[s] == model[q] && (model[r] || !model[p]), model)
+}
+
+func readDIMACS(tb testing.TB, path string) operators.CNF {
+	f, err := os.Open(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer f.Close()
+
+	cnf, err := operators.ParseDIMACS(f)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return cnf
+}
+
+func TestCDCLDIMACS(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	cnf := readDIMACS(t, "testdata/uf20-91.cnf")
+	result := CDCL(cnf)
+
+	be.Assert("uf20-91 is sat", result.Sat())
+	be.Assert("model satisfies the clauses", satisfies(cnf, result.Model))
+}
+
+func TestCDCLContext(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	ctx := context.Background()
+
+	cnf := readDIMACS(t, "testdata/uf20-91.cnf")
+	result := CDCLContext(ctx, cnf, Options{})
+	be.AssertInfo("uf20-91 is sat", result.Status == Satisfiable, result.Status)
+	be.Assert("model satisfies the clauses", satisfies(cnf, result.Model))
+
+	result = CDCLContext(ctx, pigeonhole(4), Options{})
+	be.AssertInfo("pigeonhole(4) is unsat", result.Status == Unsatisfiable, result.Status)
+}
+
+func TestCDCLContextPreprocess(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	ctx := context.Background()
+	p, q, r, s := operators.Var("p"), operators.Var("q"), operators.Var("r"), operators.Var("s")
 
-	model, ok := bdd2.FindModel(m)
-	t.Log(ok, model)
-	be.Assert("bdd has model", ok)
+	cnf := TransformTseitin(operators.And(operators.Biimplies(s, q), operators.Or(r, operators.Not(p))))
+	result := CDCLContext(ctx, cnf, Options{Preprocess: true})
+	be.AssertInfo("preprocessed tseitin cnf is sat", result.Status == Satisfiable, result.Status)
+	be.AssertInfo("model covers the original variables", len(result.Model) == len(operators.Variables(cnf)), result.Model)
+	be.Assert("model satisfies the original clauses", satisfies(cnf, result.Model))
+
+	cnf = readDIMACS(t, "testdata/uf20-91.cnf")
+	result = CDCLContext(ctx, cnf, Options{Preprocess: true})
+	be.AssertInfo("preprocessed uf20-91 is sat", result.Status == Satisfiable, result.Status)
+	be.Assert("model satisfies the original clauses", satisfies(cnf, result.Model))
+
+	result = CDCLContext(ctx, pigeonhole(4), Options{Preprocess: true})
+	be.AssertInfo("preprocessed pigeonhole(4) is unsat", result.Status == Unsatisfiable, result.Status)
+}
+
+func TestCDCLContextBudget(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	ctx := context.Background()
+
+	result := CDCLContext(ctx, pigeonhole(10), Options{ConflictBudget: 50})
+	be.AssertInfo("conflict budget results in unknown", result.Status == Unknown, result.Status)
+	be.AssertInfo("search stops at the conflict budget", result.Stats.Conflicts == 50, result.Stats)
+	be.Assert("unknown result has no model", result.Model == nil)
+
+	result = CDCLContext(ctx, pigeonhole(10), Options{PropagationBudget: 1000})
+	be.AssertInfo("propagation budget results in unknown", result.Status == Unknown, result.Status)
+	be.AssertInfo("search stops near the propagation budget", result.Stats.Propagations < 2000, result.Stats)
+}
+
+func TestCDCLContextCancel(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := CDCLContext(ctx, pigeonhole(4), Options{})
+	be.AssertInfo("cancelled context results in unknown", result.Status == Unknown, result.Status)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	result = CDCLContext(ctx, pigeonhole(12), Options{})
+	be.AssertInfo("timeout results in unknown", result.Status == Unknown, result.Status)
+	be.AssertInfo("search stops shortly after the timeout", time.Since(start) < time.Second, time.Since(start))
+}
 
-	counter, ok := bdd2.FindCounterExample(m)
-	t.Log(ok, counter)
-	be.Assert("bdd also has counterexample", ok)
+// BenchmarkCDCLDIMACS solves every DIMACS file in testdata, e.g. instances from SATLIB
+func BenchmarkCDCLDIMACS(b *testing.B) {
+	paths, err := filepath.Glob("testdata/*.cnf")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, path := range paths {
+		cnf := readDIMACS(b, path)
+		b.Run(filepath.Base(path), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				CDCL(cnf)
+			}
+		})
+	}
 }
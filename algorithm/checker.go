@@ -0,0 +1,419 @@
+package algorithm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// ErrIncompleteProof is returned by ProofChecker.Check if every lemma in the proof is valid, but the proof does not
+// derive the empty clause
+var ErrIncompleteProof = errors.New("proof does not derive the empty clause")
+
+// ProofError reports the first lemma of a proof that is neither a RUP nor a RAT clause
+type ProofError struct {
+	// Lemma is the position of the failing lemma among the added clauses in the proof, starting at 1
+	Lemma int
+	// Clause is the failing lemma in DIMACS notation
+	Clause []int
+}
+
+func (e *ProofError) Error() string {
+	return fmt.Sprintf("lemma %d %v is not implied by the preceding clauses", e.Lemma, e.Clause)
+}
+
+// ProofChecker verifies DRAT proofs of unsatisfiability of a CNF.
+// Variables in the proof refer to the variables of the CNF by their number in operators.NewNumbering, which is the
+// numbering used by operators.WriteDIMACS.
+type ProofChecker struct {
+	cnf operators.CNF
+}
+
+// NewProofChecker creates a checker for proofs of unsatisfiability of cnf
+func NewProofChecker(cnf operators.CNF) *ProofChecker {
+	return &ProofChecker{cnf: cnf}
+}
+
+// Check verifies the proof read from r by forward checking.
+// Every added lemma should either follow from the preceding clauses by reverse unit propagation (RUP),
+// or be a resolution asymmetric tautology (RAT) on its first literal.
+// Check returns a *ProofError for the first lemma that cannot be verified, or ErrIncompleteProof if the proof does
+// not derive the empty clause.
+func (c *ProofChecker) Check(r io.Reader, format ProofFormat) error {
+	state := newProofState(c.cnf)
+	reader := newProofReader(r, format)
+
+	for lemma := 0; !state.refuted; {
+		deleted, clause, err := reader.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		lits := state.literals(clause)
+
+		if deleted {
+			state.remove(lits)
+			continue
+		}
+
+		lemma++
+		if !state.rup(lits) && !state.rat(lits) {
+			return &ProofError{Lemma: lemma, Clause: clause}
+		}
+
+		state.add(lits)
+	}
+
+	if !state.refuted {
+		return ErrIncompleteProof
+	}
+
+	return nil
+}
+
+// proofState is the clause database of a proof checker.
+// It uses the propagation engine of a solver, without ever learning clauses.
+type proofState struct {
+	s        *Solver
+	numbers  map[int]int
+	active   map[*clause]bool
+	clauses  map[string][]*clause
+	refuted  bool
+	resolved []literal
+}
+
+func newProofState(cnf operators.CNF) *proofState {
+	state := &proofState{
+		s:       NewSolver(),
+		numbers: make(map[int]int),
+		active:  make(map[*clause]bool),
+		clauses: make(map[string][]*clause),
+	}
+
+	numbering := operators.NewNumbering(cnf)
+	for _, v := range operators.Variables(cnf) {
+		state.numbers[numbering.Number(v.Variable())] = state.s.newVariable(v.Variable())
+	}
+
+	for _, cnfClause := range cnf {
+		lits := make([]literal, 0, cnfClause.NumTerms())
+		satisfied := false
+		for _, t := range cnfClause.Terms() {
+			if lit, c := state.s.literal(t); c == nil {
+				lits = append(lits, lit)
+			} else if c.Value() {
+				satisfied = true
+			}
+		}
+		if !satisfied {
+			state.add(lits)
+		}
+	}
+
+	return state
+}
+
+// literals converts a clause in DIMACS notation to internal literals
+func (p *proofState) literals(clause []int) []literal {
+	lits := make([]literal, len(clause))
+	for i, number := range clause {
+		key := number
+		if key < 0 {
+			key = -key
+		}
+
+		v, ok := p.numbers[key]
+		if !ok {
+			// the proof introduces a new variable
+			v = p.s.newVariable(nil)
+			p.numbers[key] = v
+		}
+
+		lits[i] = makeLiteral(v, number < 0)
+	}
+	return lits
+}
+
+func clauseKey(lits []literal) string {
+	sorted := append([]literal(nil), lits...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	buf := make([]byte, 0, 4*len(sorted))
+	for i, l := range sorted {
+		if i == 0 || l != sorted[i-1] {
+			buf = strconv.AppendInt(buf, int64(l), 10)
+			buf = append(buf, ' ')
+		}
+	}
+	return string(buf)
+}
+
+// add inserts a clause in the database and propagates the unit clauses at the top level
+func (p *proofState) add(lits []literal) {
+	// remove duplicate literals, tautologies are always satisfied and are not stored
+	unique := make([]literal, 0, len(lits))
+	seen := make(map[literal]bool, len(lits))
+	for _, l := range lits {
+		if seen[l.negate()] {
+			return
+		}
+		if !seen[l] {
+			seen[l] = true
+			unique = append(unique, l)
+		}
+	}
+
+	c := &clause{lits: unique}
+	key := clauseKey(unique)
+	p.clauses[key] = append(p.clauses[key], c)
+	p.active[c] = true
+
+	if p.refuted {
+		return
+	}
+
+	// move the literals that are not false to the front, such that they are watched
+	sort.SliceStable(c.lits, func(i, j int) bool {
+		return p.s.value(c.lits[i]) != lFalse && p.s.value(c.lits[j]) == lFalse
+	})
+
+	switch {
+	case len(c.lits) == 0 || p.s.value(c.lits[0]) == lFalse:
+		p.refuted = true
+		return
+	case len(c.lits) == 1:
+		if p.s.value(c.lits[0]) == lUndef {
+			p.s.enqueue(c.lits[0], c)
+		}
+	default:
+		p.s.attach(c)
+		if p.s.value(c.lits[0]) == lUndef && p.s.value(c.lits[1]) == lFalse {
+			p.s.enqueue(c.lits[0], c)
+		}
+	}
+
+	if p.s.propagate() != nil {
+		p.refuted = true
+	}
+}
+
+// remove deletes a clause from the database.
+// Deletions of unit clauses, reason clauses and unknown clauses are ignored.
+func (p *proofState) remove(lits []literal) {
+	key := clauseKey(lits)
+	candidates := p.clauses[key]
+	if len(candidates) == 0 {
+		return
+	}
+
+	c := candidates[len(candidates)-1]
+	if len(c.lits) < 2 || p.s.locked(c) {
+		return
+	}
+
+	p.clauses[key] = candidates[:len(candidates)-1]
+	delete(p.active, c)
+
+	if !p.refuted {
+		p.s.detach(c)
+	}
+}
+
+// rup returns true iff assigning the negation of every literal in lits results in a conflict by unit propagation
+func (p *proofState) rup(lits []literal) bool {
+	if p.refuted {
+		return true
+	}
+
+	p.s.newDecisionLevel()
+	defer p.s.cancelUntil(0)
+
+	for _, l := range lits {
+		switch p.s.value(l) {
+		case lTrue:
+			return true
+		case lUndef:
+			p.s.enqueue(l.negate(), nil)
+		}
+	}
+
+	return p.s.propagate() != nil
+}
+
+// rat returns true iff every resolvent of lits on its first literal with a clause in the database is RUP
+func (p *proofState) rat(lits []literal) bool {
+	if len(lits) == 0 {
+		return false
+	}
+
+	pivot := lits[0]
+	for c := range p.active {
+		if !hasLiteral(c.lits, pivot.negate()) {
+			continue
+		}
+
+		p.resolved = append(p.resolved[:0], lits...)
+		tautology := false
+		for _, l := range c.lits {
+			if l == pivot.negate() {
+				continue
+			}
+			if hasLiteral(lits, l.negate()) {
+				tautology = true
+				break
+			}
+			p.resolved = append(p.resolved, l)
+		}
+
+		if !tautology && !p.rup(p.resolved) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasLiteral(lits []literal, l literal) bool {
+	for _, q := range lits {
+		if q == l {
+			return true
+		}
+	}
+	return false
+}
+
+// proofReader reads the steps of a DRAT proof in either the textual or the binary format
+type proofReader struct {
+	r      *bufio.Reader
+	format ProofFormat
+	line   int
+}
+
+func newProofReader(r io.Reader, format ProofFormat) *proofReader {
+	return &proofReader{
+		r:      bufio.NewReader(r),
+		format: format,
+		line:   1,
+	}
+}
+
+// next returns the next clause in the proof and whether it is deleted, or io.EOF at the end of the proof
+func (r *proofReader) next() (deleted bool, clause []int, err error) {
+	if r.format == BinaryDRAT {
+		return r.nextBinary()
+	}
+	return r.nextText()
+}
+
+func (r *proofReader) nextBinary() (deleted bool, clause []int, err error) {
+	kind, err := r.r.ReadByte()
+	if err != nil {
+		return false, nil, err
+	}
+
+	switch kind {
+	case 'a':
+	case 'd':
+		deleted = true
+	default:
+		return false, nil, fmt.Errorf("binary proof: unexpected byte %#x", kind)
+	}
+
+	for {
+		var u uint
+		for shift := uint(0); ; shift += 7 {
+			b, err := r.r.ReadByte()
+			if err == io.EOF {
+				return false, nil, io.ErrUnexpectedEOF
+			} else if err != nil {
+				return false, nil, err
+			}
+			u |= uint(b&127) << shift
+			if b < 128 {
+				break
+			}
+		}
+
+		if u == 0 {
+			return deleted, clause, nil
+		}
+
+		if u&1 == 1 {
+			clause = append(clause, -int(u>>1))
+		} else {
+			clause = append(clause, int(u>>1))
+		}
+	}
+}
+
+func (r *proofReader) nextText() (deleted bool, clause []int, err error) {
+	started := false
+
+	for {
+		token, err := r.token()
+		if err == io.EOF && started {
+			return false, nil, fmt.Errorf("proof line %d: %w", r.line, io.ErrUnexpectedEOF)
+		} else if err != nil {
+			return false, nil, err
+		}
+
+		switch {
+		case token == "c" && !started:
+			// skip the remainder of a comment line
+			if _, err := r.r.ReadString('\n'); err != nil && err != io.EOF {
+				return false, nil, err
+			}
+			r.line++
+		case token == "d" && !started:
+			deleted = true
+			started = true
+		default:
+			number, err := strconv.Atoi(token)
+			if err != nil {
+				return false, nil, fmt.Errorf("proof line %d: invalid literal %q", r.line, token)
+			}
+			if number == 0 {
+				return deleted, clause, nil
+			}
+			clause = append(clause, number)
+			started = true
+		}
+	}
+}
+
+// token returns the next whitespace separated token, keeping track of the line number
+func (r *proofReader) token() (string, error) {
+	var buf []byte
+	for {
+		b, err := r.r.ReadByte()
+		if err == io.EOF && len(buf) > 0 {
+			return string(buf), nil
+		} else if err != nil {
+			return "", err
+		}
+
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			if len(buf) > 0 {
+				if err := r.r.UnreadByte(); err != nil {
+					return "", err
+				}
+				return string(buf), nil
+			}
+			if b == '\n' {
+				r.line++
+			}
+		default:
+			buf = append(buf, b)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package algorithm
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+func TestCheckProof(t *testing.T) {
+	for _, format := range []ProofFormat{DRAT, BinaryDRAT} {
+		be := bdd_test.Bench{T: t}
+		cnf := pigeonhole(5)
+
+		var proof bytes.Buffer
+		sat, err := ProveUnsat(cnf, &proof, format)
+		be.Assert("pigeonhole is unsat", !sat && err == nil)
+
+		err = NewProofChecker(cnf).Check(&proof, format)
+		be.AssertInfo("proof of the solver is valid", err == nil, format, err)
+	}
+}
+
+func TestCheckProofTseitin(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c := operators.Var("a"), operators.Var("b"), operators.Var("c")
+
+	expr := operators.And(operators.Xor(a, b), operators.Xor(b, c), operators.Xor(a, c))
+	cnf := TransformTseitin(NNF(expr))
+
+	var proof bytes.Buffer
+	sat, err := ProveUnsat(cnf, &proof, DRAT)
+	be.Assert("odd cycle of exclusive disjunctions is unsat", !sat && err == nil)
+
+	err = NewProofChecker(cnf).Check(&proof, DRAT)
+	be.AssertInfo("proof of the solver is valid", err == nil, err)
+}
+
+func TestCheckProofDIMACS(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	cnf := pigeonhole(4)
+
+	var proof, dimacs bytes.Buffer
+	sat, err := ProveUnsat(cnf, &proof, DRAT)
+	be.Assert("pigeonhole is unsat", !sat && err == nil)
+
+	// the proof refers to the numbering of the DIMACS file, as expected by external checkers
+	err = operators.WriteDIMACS(&dimacs, cnf)
+	be.Assert("dimacs is written without errors", err == nil)
+
+	parsed, err := operators.ParseDIMACS(&dimacs)
+	be.Assert("dimacs is parsed without errors", err == nil)
+
+	err = NewProofChecker(parsed).Check(&proof, DRAT)
+	be.AssertInfo("proof is valid for the DIMACS file", err == nil, err)
+}
+
+func TestCheckProofRAT(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	x1, x2 := operators.IVar(1), operators.IVar(2)
+
+	cnf := operators.CNF{
+		operators.NClause{x1, x2},
+		operators.NClause{x1.Negate(), x2},
+		operators.NClause{x1, x2.Negate()},
+		operators.NClause{x1.Negate(), x2.Negate()},
+	}
+
+	// the first lemma introduces a new variable 3: it is not RUP, but it is RAT on 3
+	proof := "c blocked clause\n3 0\nd 3 0\n1 0\n0\n"
+
+	err := NewProofChecker(cnf).Check(strings.NewReader(proof), DRAT)
+	be.AssertInfo("proof with RAT lemma is valid", err == nil, err)
+}
+
+func TestCheckProofInvalid(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	x1, x2 := operators.IVar(1), operators.IVar(2)
+
+	cnf := operators.CNF{
+		operators.NClause{x1, x2},
+		operators.NClause{x1.Negate(), x2},
+	}
+
+	err := NewProofChecker(cnf).Check(strings.NewReader("2 0\n-2 0\n0\n"), DRAT)
+
+	var proofErr *ProofError
+	be.AssertInfo("second lemma fails", errors.As(err, &proofErr) && proofErr.Lemma == 2, err)
+
+	err = NewProofChecker(cnf).Check(strings.NewReader("2 0\n"), DRAT)
+	be.AssertInfo("proof without empty clause is incomplete", err == ErrIncompleteProof, err)
+
+	err = NewProofChecker(cnf).Check(strings.NewReader("2 x 0\n"), DRAT)
+	be.AssertInfo("malformed proof is rejected", err != nil && err != ErrIncompleteProof && !errors.As(err, &proofErr), err)
+}
@@ -43,6 +43,61 @@ func CNF(e operators.Expression) (cnf operators.CNF) {
 			operators.NClause{leftVar.Negate(), l},
 			operators.NClause{leftVar.Negate(), r},
 		}...)
+	case *operators.NDisjunction:
+		// a single clause for the disjunction of all operands
+		operands := e.RightChild().(*operators.NDisjunction).Operands
+		clause := operators.NClause{leftVar.Negate()}
+		for _, o := range operands {
+			clause = append(clause, o.(operators.Term))
+			cnf = append(cnf, operators.NClause{leftVar, o.(operators.Term).Negate()})
+		}
+		cnf = append(cnf, clause)
+	case *operators.NConjunction:
+		operands := e.RightChild().(*operators.NConjunction).Operands
+		clause := operators.NClause{leftVar}
+		for _, o := range operands {
+			clause = append(clause, o.(operators.Term).Negate())
+			cnf = append(cnf, operators.NClause{leftVar.Negate(), o.(operators.Term)})
+		}
+		cnf = append(cnf, clause)
+	case *operators.Implication:
+		imp := e.RightChild().(*operators.Implication)
+		l, r := imp.LeftChild().(operators.Term), imp.RightChild().(operators.Term)
+		cnf = append(cnf, operators.CNF{
+			operators.NClause{leftVar.Negate(), l.Negate(), r},
+			operators.NClause{leftVar, l},
+			operators.NClause{leftVar, r.Negate()},
+		}...)
+	case *operators.ExclusiveDisjunction:
+		xor := e.RightChild().(*operators.ExclusiveDisjunction)
+		l, r := xor.LeftChild().(operators.Term), xor.RightChild().(operators.Term)
+		cnf = append(cnf, operators.CNF{
+			operators.NClause{leftVar.Negate(), l, r},
+			operators.NClause{leftVar.Negate(), l.Negate(), r.Negate()},
+			operators.NClause{leftVar, l.Negate(), r},
+			operators.NClause{leftVar, l, r.Negate()},
+		}...)
+	case *operators.Biimplication:
+		bi := e.RightChild().(*operators.Biimplication)
+		l, r := bi.LeftChild().(operators.Term), bi.RightChild().(operators.Term)
+		cnf = append(cnf, operators.CNF{
+			operators.NClause{leftVar.Negate(), l.Negate(), r},
+			operators.NClause{leftVar.Negate(), l, r.Negate()},
+			operators.NClause{leftVar, l, r},
+			operators.NClause{leftVar, l.Negate(), r.Negate()},
+		}...)
+	case *operators.Choice:
+		// if-then-else on the variable of the choice, the last two clauses are redundant but strengthen propagation
+		ite := e.RightChild().(*operators.Choice)
+		c, l, r := ite.Var, ite.LeftChild().(operators.Term), ite.RightChild().(operators.Term)
+		cnf = append(cnf, operators.CNF{
+			operators.NClause{leftVar.Negate(), c.Negate(), l},
+			operators.NClause{leftVar.Negate(), c, r},
+			operators.NClause{leftVar, c.Negate(), l.Negate()},
+			operators.NClause{leftVar, c, r.Negate()},
+			operators.NClause{leftVar.Negate(), l, r},
+			operators.NClause{leftVar, l.Negate(), r.Negate()},
+		}...)
 	case operators.Constant:
 		c := e.RightChild().(operators.Constant)
 		var t operators.Term
@@ -62,44 +117,110 @@ func CNF(e operators.Expression) (cnf operators.CNF) {
 	return
 }
 
+// DeMorgan pushes the negations in a normalized expression e to the leafs.
+// Every subexpression is transformed once per polarity, such that shared subexpressions remain shared, and nodes
+// without negations below them are reused instead of copied.
 func DeMorgan(e operators.Expression) operators.Expression {
+	return deMorgan(e, false, make(map[deMorganKey]operators.Expression))
+}
+
+type deMorganKey struct {
+	e       operators.Expression
+	negated bool
+}
+
+func deMorgan(e operators.Expression, negated bool, memo map[deMorganKey]operators.Expression) operators.Expression {
+	key := deMorganKey{e, negated}
+	if result, ok := memo[key]; ok {
+		return result
+	}
+
+	var result operators.Expression
 	if neg, ok := e.(*operators.Negation); ok {
-		child := neg.RightChild()
-		switch child.(type) {
+		result = deMorgan(neg.RightChild(), !negated, memo)
+	} else if negated {
+		switch e.(type) {
 		case *operators.Conjunction:
-			return &operators.Disjunction{
-				A: DeMorgan(operators.Not(child.LeftChild())),
-				B: DeMorgan(operators.Not(child.RightChild())),
+			result = &operators.Disjunction{
+				A: deMorgan(e.LeftChild(), true, memo),
+				B: deMorgan(e.RightChild(), true, memo),
 			}
 		case *operators.Disjunction:
-			return &operators.Conjunction{
-				A: DeMorgan(operators.Not(child.LeftChild())),
-				B: DeMorgan(operators.Not(child.RightChild())),
+			result = &operators.Conjunction{
+				A: deMorgan(e.LeftChild(), true, memo),
+				B: deMorgan(e.RightChild(), true, memo),
 			}
+		case *operators.NConjunction:
+			operands, _ := deMorganOperands(e.(*operators.NConjunction).Operands, true, memo)
+			result = &operators.NDisjunction{Operands: operands}
+		case *operators.NDisjunction:
+			operands, _ := deMorganOperands(e.(*operators.NDisjunction).Operands, true, memo)
+			result = &operators.NConjunction{Operands: operands}
 		case operators.Constant:
 			// boolean constants are easily negated
-			return child.(operators.Constant).Negate()
+			result = e.(operators.Constant).Negate()
+		case *operators.Cardinality:
+			result = e.(*operators.Cardinality).Complement()
+		case *operators.PseudoBoolean:
+			result = e.(*operators.PseudoBoolean).Complement()
 		case operators.Variable:
-			return e
+			result = e.(operators.Variable).Negate()
 		default:
 			panic("unsupported type for DeMorgan, should be normalized")
 		}
+	} else if c, ok := e.(*operators.NConjunction); ok {
+		result = e
+		if operands, changed := deMorganOperands(c.Operands, false, memo); changed {
+			result = &operators.NConjunction{Operands: operands}
+		}
+	} else if d, ok := e.(*operators.NDisjunction); ok {
+		result = e
+		if operands, changed := deMorganOperands(d.Operands, false, memo); changed {
+			result = &operators.NDisjunction{Operands: operands}
+		}
+	} else if e.LeftChild() == nil && e.RightChild() == nil {
+		result = e
 	} else {
+		var left, right operators.Expression
 		if e.LeftChild() != nil {
-			e.SetLeftChild(DeMorgan(e.LeftChild()))
+			left = deMorgan(e.LeftChild(), false, memo)
 		}
 		if e.RightChild() != nil {
-			e.SetRightChild(DeMorgan(e.RightChild()))
+			right = deMorgan(e.RightChild(), false, memo)
 		}
-		return e
+
+		if left == e.LeftChild() && right == e.RightChild() {
+			result = e
+		} else if op, ok := e.(operators.Operator); ok {
+			result = op.Join(left, right)
+		} else {
+			e.SetLeftChild(left)
+			e.SetRightChild(right)
+			result = e
+		}
+	}
+
+	memo[key] = result
+	return result
+}
+
+// deMorganOperands applies DeMorgan to every operand of an n-ary operator, and reports whether an operand changed
+func deMorganOperands(operands []operators.Expression, negated bool, memo map[deMorganKey]operators.Expression) ([]operators.Expression, bool) {
+	result := make([]operators.Expression, len(operands))
+	changed := false
+	for i, o := range operands {
+		result[i] = deMorgan(o, negated, memo)
+		changed = changed || result[i] != o
 	}
+	return result, changed
 }
 
 // NNF converts a given expression to negation-normal-form by replacing every operator to an equivalent disjunction/conjunction/negation
-// and applying demorgan on the expressions to push negation to leafs
+// and applying demorgan on the expressions to push negation to leafs.
+// Shared subexpressions are normalized once, and remain shared in the result.
 func NNF(e operators.Expression) operators.Expression {
 	// convert to conjunctions, negations and disjunctions
-	normal := e.Normalize()
+	normal := operators.NewFactory().Normalize(e)
 
 	return DeMorgan(normal)
 }
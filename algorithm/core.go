@@ -0,0 +1,37 @@
+package algorithm
+
+import "github.com/timbeurskens/gobdd/operators"
+
+// UnsatCore returns a subset of the clauses in cnf that is unsatisfiable on its own.
+// Every clause is extended with the negation of a fresh selector variable, after which the clauses are solved under
+// the assumption that every selector is true. The selectors in the final conflict identify the clauses in the core.
+// If cnf is satisfiable, sat is true and the core is nil.
+func UnsatCore(cnf operators.CNF) (core operators.CNF, sat bool) {
+	s := NewSolver()
+
+	selectors := make([]operators.Term, len(cnf))
+	clauses := make(map[operators.Term]operators.CNFClause, len(cnf))
+
+	for i, clause := range cnf {
+		selectors[i] = operators.IncVar()
+		clauses[selectors[i]] = clause
+
+		terms := make(operators.NClause, 0, clause.NumTerms()+1)
+		terms = append(terms, clause.Terms()...)
+		terms = append(terms, selectors[i].Negate())
+
+		s.AddClause(terms)
+	}
+
+	if s.Solve(selectors...) {
+		return nil, true
+	}
+
+	failed := s.Core()
+	core = make(operators.CNF, 0, len(failed))
+	for _, selector := range failed {
+		core = append(core, clauses[selector])
+	}
+
+	return core, false
+}
@@ -0,0 +1,404 @@
+package algorithm
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// CountModels returns the number of models of cnf over its variables.
+// If project is not empty, CountModels returns the number of assignments of the variables in project that can be
+// extended to a model of cnf instead, e.g. ignoring the auxiliary variables introduced by TransformTseitin.
+// Variables in project that do not occur in cnf are free, and double the count.
+//
+// The count is computed by a DPLL-style search, which splits the remaining clauses into independent components
+// and caches the count of every component (as in sharpSAT).
+func CountModels(cnf operators.CNF, project []operators.Variable) *big.Int {
+	c := newCounter()
+
+	for _, v := range project {
+		c.projected[c.variable(v)] = true
+	}
+
+	for _, t := range operators.Variables(cnf) {
+		v := c.variable(t.Variable())
+		if len(project) == 0 {
+			c.projected[v] = true
+		}
+	}
+
+	for _, cnfClause := range cnf {
+		if !c.addClause(cnfClause) {
+			return big.NewInt(0)
+		}
+	}
+
+	vars := make([]int, len(c.projected))
+	for v := range vars {
+		vars[v] = v
+	}
+
+	clauses := make([]int, len(c.clauses))
+	for i := range clauses {
+		clauses[i] = i
+	}
+
+	return c.count(vars, clauses, 0)
+}
+
+// counter is the state of a model count: the clauses, their occurrences and the current partial assignment
+type counter struct {
+	index     map[interface{}]int
+	projected []bool
+	assigns   []lbool
+	trail     []literal
+
+	clauses [][]literal
+	occurs  [][]int
+
+	// stamps mark the residual clauses, the clauses and variables of a component during a split
+	stamp       int
+	residual    []int
+	component   []int
+	varStamps   []int
+	occurrences []int
+
+	cache map[string]*big.Int
+}
+
+func newCounter() *counter {
+	return &counter{
+		index: make(map[interface{}]int),
+		cache: make(map[string]*big.Int),
+	}
+}
+
+func (c *counter) variable(v operators.Variable) int {
+	key := operators.VariableKey(v)
+	if i, ok := c.index[key]; ok {
+		return i
+	}
+
+	i := len(c.projected)
+	c.index[key] = i
+	c.projected = append(c.projected, false)
+	c.assigns = append(c.assigns, lUndef)
+	c.varStamps = append(c.varStamps, 0)
+	c.occurrences = append(c.occurrences, 0)
+	c.occurs = append(c.occurs, nil, nil)
+	return i
+}
+
+// addClause adds a clause, unit clauses are assigned immediately.
+// It returns false if the clause is empty or contradicts a unit clause.
+func (c *counter) addClause(cnfClause operators.CNFClause) bool {
+	var lits []literal
+
+	for _, t := range cnfClause.Terms() {
+		negative := false
+		for {
+			if neg, ok := t.(*operators.Negation); ok {
+				negative = !negative
+				t = neg.Negate()
+			} else {
+				break
+			}
+		}
+
+		if constant, ok := t.(operators.Constant); ok {
+			if constant.Value() != negative {
+				return true
+			}
+			continue
+		}
+
+		l := makeLiteral(c.variable(t.Variable()), negative)
+		if hasLiteral(lits, l.negate()) {
+			// tautology
+			return true
+		}
+		if !hasLiteral(lits, l) {
+			lits = append(lits, l)
+		}
+	}
+
+	switch {
+	case len(lits) == 0:
+		return false
+	case len(lits) == 1:
+		if c.value(lits[0]) == lFalse {
+			return false
+		}
+		if c.value(lits[0]) == lUndef {
+			c.assign(lits[0])
+		}
+	}
+
+	ci := len(c.clauses)
+	c.clauses = append(c.clauses, lits)
+	c.residual = append(c.residual, 0)
+	c.component = append(c.component, 0)
+	for _, l := range lits {
+		c.occurs[l] = append(c.occurs[l], ci)
+	}
+
+	return true
+}
+
+func (c *counter) value(l literal) lbool {
+	v := c.assigns[l.variable()]
+	if v == lUndef || !l.negative() {
+		return v
+	}
+	if v == lTrue {
+		return lFalse
+	}
+	return lTrue
+}
+
+func (c *counter) assign(l literal) {
+	c.assigns[l.variable()] = liftBool(!l.negative())
+	c.trail = append(c.trail, l)
+}
+
+// undo unassigns every literal on the trail after position mark
+func (c *counter) undo(mark int) {
+	for _, l := range c.trail[mark:] {
+		c.assigns[l.variable()] = lUndef
+	}
+	c.trail = c.trail[:mark]
+}
+
+func (c *counter) satisfied(ci int) bool {
+	for _, l := range c.clauses[ci] {
+		if c.value(l) == lTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// propagate assigns the unit clauses that follow from the assignments on the trail from position qhead.
+// It returns false if a clause is falsified.
+func (c *counter) propagate(qhead int) bool {
+	for ; qhead < len(c.trail); qhead++ {
+		falsified := c.trail[qhead].negate()
+
+		for _, ci := range c.occurs[falsified] {
+			unassigned, last, satisfied := 0, undefLiteral, false
+			for _, l := range c.clauses[ci] {
+				switch c.value(l) {
+				case lTrue:
+					satisfied = true
+				case lUndef:
+					unassigned++
+					last = l
+				}
+				if satisfied {
+					break
+				}
+			}
+
+			switch {
+			case satisfied:
+			case unassigned == 0:
+				return false
+			case unassigned == 1:
+				c.assign(last)
+			}
+		}
+	}
+	return true
+}
+
+// count returns the number of projected models of a component, after propagating the trail from position qhead
+func (c *counter) count(vars, clauses []int, qhead int) *big.Int {
+	if !c.propagate(qhead) {
+		return big.NewInt(0)
+	}
+
+	// the residual clauses are the clauses of the component that are not satisfied yet
+	c.stamp++
+	residual := make([]int, 0, len(clauses))
+	for _, ci := range clauses {
+		if !c.satisfied(ci) {
+			c.residual[ci] = c.stamp
+			residual = append(residual, ci)
+		}
+	}
+
+	for _, ci := range residual {
+		for _, l := range c.clauses[ci] {
+			c.varStamps[l.variable()] = c.stamp
+		}
+	}
+
+	// unassigned projected variables that no longer occur in any clause can take either value
+	free := 0
+	for _, v := range vars {
+		if c.assigns[v] == lUndef && c.varStamps[v] != c.stamp && c.projected[v] {
+			free++
+		}
+	}
+
+	result := new(big.Int).Lsh(big.NewInt(1), uint(free))
+
+	for _, component := range c.split(residual) {
+		key := componentKey(component)
+
+		n, ok := c.cache[key]
+		if !ok {
+			n = c.countComponent(component)
+			c.cache[key] = n
+		}
+
+		if n.Sign() == 0 {
+			return n
+		}
+		result.Mul(result, n)
+	}
+
+	return result
+}
+
+type countComponent struct {
+	vars    []int
+	clauses []int
+}
+
+// componentKey identifies a component by its unassigned variables and unsatisfied clauses, which determine the
+// remaining clauses of the component
+func componentKey(component countComponent) string {
+	buf := make([]byte, 0, 4*(len(component.vars)+len(component.clauses)))
+	for _, v := range component.vars {
+		buf = strconv.AppendInt(buf, int64(v), 36)
+		buf = append(buf, ' ')
+	}
+	buf = append(buf, '|')
+	for _, ci := range component.clauses {
+		buf = strconv.AppendInt(buf, int64(ci), 36)
+		buf = append(buf, ' ')
+	}
+	return string(buf)
+}
+
+// split partitions the residual clauses into components that do not share unassigned variables
+func (c *counter) split(residual []int) []countComponent {
+	var components []countComponent
+
+	c.stamp++
+	for _, start := range residual {
+		if c.component[start] == c.stamp {
+			continue
+		}
+
+		var component countComponent
+		c.component[start] = c.stamp
+		queue := []int{start}
+
+		for len(queue) > 0 {
+			ci := queue[0]
+			queue = queue[1:]
+			component.clauses = append(component.clauses, ci)
+
+			for _, l := range c.clauses[ci] {
+				v := l.variable()
+				if c.assigns[v] != lUndef || c.varStamps[v] == c.stamp {
+					continue
+				}
+
+				c.varStamps[v] = c.stamp
+				component.vars = append(component.vars, v)
+
+				for _, occ := range [][]int{c.occurs[2*v], c.occurs[2*v+1]} {
+					for _, other := range occ {
+						if c.residual[other] == c.stamp-1 && c.component[other] != c.stamp {
+							c.component[other] = c.stamp
+							queue = append(queue, other)
+						}
+					}
+				}
+			}
+		}
+
+		sort.Ints(component.vars)
+		sort.Ints(component.clauses)
+		components = append(components, component)
+	}
+
+	return components
+}
+
+// countComponent branches on the projected variable that occurs most often in the component.
+// A component without projected variables counts 1 if it is satisfiable.
+func (c *counter) countComponent(component countComponent) *big.Int {
+	for _, v := range component.vars {
+		c.occurrences[v] = 0
+	}
+	for _, ci := range component.clauses {
+		for _, l := range c.clauses[ci] {
+			c.occurrences[l.variable()]++
+		}
+	}
+
+	branch := -1
+	for _, v := range component.vars {
+		if c.projected[v] && (branch < 0 || c.occurrences[v] > c.occurrences[branch]) {
+			branch = v
+		}
+	}
+
+	mark := len(c.trail)
+
+	if branch < 0 {
+		sat := c.satisfiable(component.clauses, mark)
+		c.undo(mark)
+		if sat {
+			return big.NewInt(1)
+		}
+		return big.NewInt(0)
+	}
+
+	total := new(big.Int)
+	for _, negative := range []bool{false, true} {
+		c.assign(makeLiteral(branch, negative))
+		total.Add(total, c.count(component.vars, component.clauses, mark))
+		c.undo(mark)
+	}
+
+	return total
+}
+
+// satisfiable searches for an assignment that satisfies the clauses, after propagating the trail from qhead
+func (c *counter) satisfiable(clauses []int, qhead int) bool {
+	if !c.propagate(qhead) {
+		return false
+	}
+
+	for _, ci := range clauses {
+		if c.satisfied(ci) {
+			continue
+		}
+
+		for _, l := range c.clauses[ci] {
+			if c.value(l) != lUndef {
+				continue
+			}
+
+			for _, decision := range []literal{l, l.negate()} {
+				mark := len(c.trail)
+				c.assign(decision)
+				sat := c.satisfiable(clauses, mark)
+				c.undo(mark)
+				if sat {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,57 @@
+package algorithm
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+func TestCountModels(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c, d := operators.Var("a"), operators.Var("b"), operators.Var("c"), operators.Var("d")
+
+	cnf := operators.CNF{
+		operators.NClause{a, b, c},
+		operators.NClause{a.Negate(), b.Negate()},
+	}
+
+	count := CountModels(cnf, nil)
+	be.AssertInfo("a or b or c and not a and b has 5 models", count.Int64() == 5, count)
+
+	count = CountModels(cnf, []operators.Variable{a, b})
+	be.AssertInfo("projection onto a and b has 3 models", count.Int64() == 3, count)
+
+	count = CountModels(cnf, []operators.Variable{a, b, c, d})
+	be.AssertInfo("free variable d doubles the count", count.Int64() == 10, count)
+
+	count = CountModels(pigeonhole(4), nil)
+	be.AssertInfo("unsat cnf has no models", count.Sign() == 0, count)
+}
+
+func TestCountModelsTseitin(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c := operators.Var("a"), operators.Var("b"), operators.Var("c")
+
+	cnf := TransformTseitin(NNF(operators.Xor(a, operators.Xor(b, c))))
+
+	count := CountModels(cnf, []operators.Variable{a, b, c})
+	be.AssertInfo("a xor b xor c has 4 models over a, b and c", count.Int64() == 4, count)
+}
+
+func TestCountModelsComponents(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	// 200 independent clauses (x_i or y_i) have 3^200 models
+	cnf := make(operators.CNF, 200)
+	for i := range cnf {
+		cnf[i] = operators.NClause{operators.Var(fmt.Sprintf("x_%d", i)), operators.Var(fmt.Sprintf("y_%d", i))}
+	}
+
+	expected := new(big.Int).Exp(big.NewInt(3), big.NewInt(200), nil)
+
+	count := CountModels(cnf, nil)
+	be.AssertInfo("independent components are multiplied", count.Cmp(expected) == 0, count)
+}
@@ -0,0 +1,213 @@
+package algorithm
+
+import (
+	"context"
+	"sync"
+
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// CubeOptions configures the look-ahead splitting of Cubes
+type CubeOptions struct {
+	// Depth is the maximum number of decisions in a cube, such that there are at most 2^Depth cubes.
+	// Zero selects a depth of 10.
+	Depth int
+	// Variables are the variables to split on, e.g. the bits of the factors of a multiplication.
+	// If it is empty, every variable of the CNF can be split on.
+	Variables []operators.Variable
+	// Candidates is the maximum number of variables that are looked ahead on in every split, zero means all of them
+	Candidates int
+}
+
+// Cubes partitions cnf into cubes by look-ahead splitting: every split decides the variable whose assignment, in
+// both directions, propagates the most literals. Literals that lead to a conflict are failed literals: their
+// negation is added to the cube, and if both directions fail, the cube is refuted and omitted.
+// Every cube is a list of assumptions, and cnf is satisfiable iff it is satisfiable under one of the cubes.
+// The cubes can be solved by CubeAndConquer, or written in the iCNF format with operators.WriteICNF.
+func Cubes(cnf operators.CNF, opts CubeOptions) [][]operators.Term {
+	depth := opts.Depth
+	if depth == 0 {
+		depth = 10
+	}
+
+	s := NewSolver()
+	if !s.AddCNF(cnf) {
+		return nil
+	}
+
+	var candidates []int
+	if len(opts.Variables) > 0 {
+		for _, v := range opts.Variables {
+			if lit, c := s.literal(v); c == nil {
+				candidates = append(candidates, lit.variable())
+			}
+		}
+	} else {
+		for v := range s.variables {
+			candidates = append(candidates, v)
+		}
+	}
+
+	l := &lookahead{s: s, candidates: candidates, limit: opts.Candidates}
+
+	var cubes [][]operators.Term
+	l.split(nil, depth, func(cube []literal) {
+		terms := make([]operators.Term, len(cube))
+		for i, lit := range cube {
+			terms[i] = s.term(lit)
+		}
+		cubes = append(cubes, terms)
+	})
+
+	return cubes
+}
+
+// lookahead splits the search space of a Solver. The literals of the current cube are decisions on the trail of the
+// solver, every literal at its own decision level.
+type lookahead struct {
+	s          *Solver
+	candidates []int
+	limit      int
+}
+
+// assume decides lit at a new decision level, and returns false if propagation leads to a conflict
+func (l *lookahead) assume(lit literal) bool {
+	l.s.newDecisionLevel()
+	l.s.enqueue(lit, nil)
+	return l.s.propagate() == nil
+}
+
+// probe returns the number of literals that are propagated by lit, or -1 if lit is a failed literal
+func (l *lookahead) probe(lit literal) int {
+	level, size := l.s.decisionLevel(), len(l.s.trail)
+	defer l.s.cancelUntil(level)
+
+	if !l.assume(lit) {
+		return -1
+	}
+	return len(l.s.trail) - size
+}
+
+// split emits the cubes below the current cube, with at most depth more decisions
+func (l *lookahead) split(cube []literal, depth int, emit func([]literal)) {
+	s := l.s
+	level := s.decisionLevel()
+	defer s.cancelUntil(level)
+
+	best, bestScore := undefLiteral, -1
+
+	for restart := depth > 0; restart; {
+		restart = false
+		best, bestScore = undefLiteral, -1
+
+		probed := 0
+		for _, v := range l.candidates {
+			if s.assigns[v] != lUndef {
+				continue
+			}
+			if l.limit > 0 && probed >= l.limit {
+				break
+			}
+			probed++
+
+			pos, neg := makeLiteral(v, false), makeLiteral(v, true)
+			p, n := l.probe(pos), l.probe(neg)
+
+			if p < 0 && n < 0 {
+				// the cube is refuted
+				return
+			}
+
+			if p < 0 || n < 0 {
+				// the other direction is implied by the cube, which changes the look-ahead of every candidate
+				implied := pos
+				if p < 0 {
+					implied = neg
+				}
+				cube = append(cube, implied)
+				if !l.assume(implied) {
+					return
+				}
+				restart = true
+				break
+			}
+
+			// prefer balanced splits that propagate much in both directions
+			if score := p*n*1024 + p + n; score > bestScore {
+				best, bestScore = pos, score
+			}
+		}
+	}
+
+	if best == undefLiteral {
+		emit(append([]literal(nil), cube...))
+		return
+	}
+
+	base := s.decisionLevel()
+	for _, lit := range []literal{best, best.negate()} {
+		if l.assume(lit) {
+			l.split(append(cube, lit), depth-1, emit)
+		}
+		s.cancelUntil(base)
+	}
+}
+
+// CubeAndConquer solves cnf under every cube independently, by workers incremental solvers in parallel. Every worker
+// adds cnf once, and keeps its learnt clauses from one cube to the next. The first model that is found is returned
+// after the other workers are cancelled. cnf is unsatisfiable if it is unsatisfiable under every cube, and the status
+// is Unknown if ctx is done first. The statistics of the result are the sum over all workers.
+func CubeAndConquer(ctx context.Context, cnf operators.CNF, cubes [][]operators.Term, workers int) Result {
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	queue := make(chan []operators.Term, len(cubes))
+	for _, cube := range cubes {
+		queue <- cube
+	}
+	close(queue)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	result := Result{Status: Unsatisfiable}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			s := NewSolver()
+			s.AddCNF(cnf)
+
+			for cube := range queue {
+				status := s.SolveContext(ctx, cube...)
+
+				mu.Lock()
+				switch {
+				case status == Satisfiable && result.Status != Satisfiable:
+					result.Status, result.Model = Satisfiable, s.Model()
+					cancel()
+				case status == Unknown && result.Status == Unsatisfiable:
+					result.Status = Unknown
+				}
+				mu.Unlock()
+
+				if status != Unsatisfiable {
+					break
+				}
+			}
+
+			mu.Lock()
+			result.Stats = result.Stats.add(s.Stats())
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	return result
+}
@@ -0,0 +1,67 @@
+package algorithm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+func TestCubes(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	cnf := readDIMACS(t, "testdata/uf20-91.cnf")
+	variables := make([]operators.Variable, 0, 20)
+	for _, v := range operators.Variables(cnf) {
+		variables = append(variables, v.Variable())
+	}
+
+	cubes := Cubes(cnf, CubeOptions{Depth: 4})
+	be.AssertInfo("there are at most 2^4 cubes", len(cubes) > 1 && len(cubes) <= 16, len(cubes))
+
+	// the cubes are disjoint, and every model satisfies a cube
+	sum := new(big.Int)
+	for _, cube := range cubes {
+		withCube := append(operators.CNF{}, cnf...)
+		for _, t := range cube {
+			withCube = append(withCube, t)
+		}
+		sum.Add(sum, CountModels(withCube, variables))
+	}
+
+	expected := CountModels(cnf, variables)
+	be.AssertInfo("the cubes partition the models", sum.Cmp(expected) == 0, sum, expected)
+
+	result := CubeAndConquer(context.Background(), cnf, cubes, 4)
+	be.Assert("uf20-91 is sat", result.Sat())
+	be.Assert("model satisfies the clauses", satisfies(cnf, result.Model))
+}
+
+func TestCubesRestricted(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	cnf := pigeonhole(5)
+	first := operators.Variables(cnf)[:3]
+
+	cubes := Cubes(cnf, CubeOptions{Depth: 8, Variables: []operators.Variable{first[0].Variable(), first[1].Variable(), first[2].Variable()}})
+	for _, cube := range cubes {
+		for _, t := range cube {
+			v := t.Variable()
+			be.AssertInfo("cubes only split on the given variables", v == first[0] || v == first[1] || v == first[2], cube)
+		}
+	}
+
+	result := CubeAndConquer(context.Background(), cnf, cubes, 2)
+	be.AssertInfo("pigeonhole(5) is unsat under every cube", result.Status == Unsatisfiable, result.Status)
+
+	be.Assert("an unsatisfiable cnf has no cubes", len(Cubes(operators.CNF{operators.NClause{}}, CubeOptions{})) == 0)
+	be.AssertInfo("an empty cnf has one empty cube", len(Cubes(operators.CNF{}, CubeOptions{})) == 1, Cubes(operators.CNF{}, CubeOptions{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result = CubeAndConquer(ctx, pigeonhole(6), Cubes(pigeonhole(6), CubeOptions{Depth: 2}), 2)
+	be.AssertInfo("cube and conquer is unknown when ctx is done", result.Status == Unknown, result.Status)
+}
@@ -0,0 +1,46 @@
+package algorithm
+
+import (
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// EnumerateModels calls fn for every model of cnf, until fn returns false.
+// If project is not empty, the models are projected onto the variables in project: fn is called once for every
+// assignment of these variables that can be extended to a model of cnf, e.g. ignoring the auxiliary variables
+// introduced by TransformTseitin. Otherwise, every model assigns every variable of cnf.
+// Models are enumerated by the incremental Solver, where every model is excluded from the search by a blocking clause.
+func EnumerateModels(cnf operators.CNF, project []operators.Variable, fn func(operators.Model) bool) {
+	if len(project) == 0 {
+		for _, t := range operators.Variables(cnf) {
+			project = append(project, t.Variable())
+		}
+	}
+
+	s := NewSolver()
+	s.AddCNF(cnf)
+
+	for _, v := range project {
+		// make sure that the solver assigns every projected variable, even if it does not occur in cnf
+		s.literal(v)
+	}
+
+	for s.Solve() {
+		model := make(operators.Model, len(project))
+		blocking := make(operators.NClause, len(project))
+
+		for i, v := range project {
+			value := s.modelValue(v)
+			model[v] = value
+
+			if value {
+				blocking[i] = v.Negate()
+			} else {
+				blocking[i] = v
+			}
+		}
+
+		if !fn(model) || !s.AddClause(blocking) {
+			return
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package algorithm
+
+import (
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+func TestEnumerateModels(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c := operators.Var("a"), operators.Var("b"), operators.Var("c")
+
+	cnf := operators.CNF{
+		operators.NClause{a, b, c},
+		operators.NClause{a.Negate(), b.Negate()},
+	}
+
+	seen := make(map[[3]bool]bool)
+	EnumerateModels(cnf, nil, func(model operators.Model) bool {
+		be.AssertInfo("model satisfies the clauses", satisfies(cnf, model), model)
+		seen[[3]bool{model[a], model[b], model[c]}] = true
+		return true
+	})
+
+	be.AssertInfo("a or b or c and not a and b has 5 models", len(seen) == 5, seen)
+}
+
+func TestEnumerateModelsProjection(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c, d := operators.Var("a"), operators.Var("b"), operators.Var("c"), operators.Var("d")
+
+	expr := operators.Xor(a, operators.Xor(b, c))
+	cnf := TransformTseitin(NNF(expr))
+
+	count := 0
+	EnumerateModels(cnf, []operators.Variable{a, b, c}, func(model operators.Model) bool {
+		be.AssertInfo("model is projected", len(model) == 3, model)
+		be.AssertInfo("model satisfies a xor b xor c", model[a] != (model[b] != model[c]), model)
+		count++
+		return true
+	})
+	be.AssertInfo("a xor b xor c has 4 models over a, b and c", count == 4, count)
+
+	count = 0
+	EnumerateModels(cnf, []operators.Variable{a, d}, func(model operators.Model) bool {
+		count++
+		return true
+	})
+	be.AssertInfo("variables that do not occur in the cnf are free", count == 4, count)
+
+	count = 0
+	EnumerateModels(cnf, []operators.Variable{a, b, c}, func(model operators.Model) bool {
+		count++
+		return count < 2
+	})
+	be.AssertInfo("enumeration stops when fn returns false", count == 2, count)
+
+	EnumerateModels(pigeonhole(3), nil, func(model operators.Model) bool {
+		be.Assert("unsat cnf has no models", false)
+		return true
+	})
+}
@@ -0,0 +1,311 @@
+package algorithm
+
+import (
+	"context"
+	"math"
+	"math/rand"
+
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// LocalSearchAlgorithm selects the heuristic of the local search
+type LocalSearchAlgorithm int
+
+const (
+	// WalkSAT flips a variable of a random falsified clause that breaks no other clause, or otherwise a random
+	// variable of the clause with probability Noise, and the variable that breaks the fewest clauses with probability
+	// 1-Noise (Selman, Kautz and Cohen, 1994)
+	WalkSAT LocalSearchAlgorithm = iota
+	// ProbSAT flips a variable of a random falsified clause with a probability of (ε + break)^-Noise, where break is
+	// the number of clauses that the flip falsifies (Balint and Schöning, 2012)
+	ProbSAT
+)
+
+// LocalSearchOptions configures LocalSearch
+type LocalSearchOptions struct {
+	Algorithm LocalSearchAlgorithm
+	// Noise is the probability of a random walk step for WalkSAT, and the exponent of the break count for ProbSAT.
+	// Zero selects the default: 0.567 for WalkSAT and 2.06 for ProbSAT.
+	Noise float64
+	// FlipBudget is the maximum number of flips, zero means unlimited
+	FlipBudget int
+	// Seed initializes the random number generator, such that the search can be repeated
+	Seed int64
+}
+
+// defaultNoise is the noise of every algorithm if no noise is given, as tuned for random 3-SAT
+var defaultNoise = map[LocalSearchAlgorithm]float64{
+	WalkSAT: 0.567,
+	ProbSAT: 2.06,
+}
+
+// probSATEpsilon keeps the probability of a variable with a break count of zero finite
+const probSATEpsilon = 0.9
+
+// LocalSearch searches for a model of cnf by stochastic local search: starting from a random assignment, it flips
+// variables of falsified clauses until every clause is satisfied. The search is incomplete: it cannot show that a CNF
+// is unsatisfiable, so the status of the result is Unknown if no model is found within the flip budget.
+func LocalSearch(cnf operators.CNF, opts LocalSearchOptions) Result {
+	return LocalSearchContext(context.Background(), cnf, opts)
+}
+
+// LocalSearchContext is like LocalSearch, but also stops the search when ctx is done.
+// Without a flip budget, the search on an unsatisfiable CNF only stops when ctx is done.
+func LocalSearchContext(ctx context.Context, cnf operators.CNF, opts LocalSearchOptions) Result {
+	ls, ok := newLocalSearch(cnf, opts)
+	if !ok {
+		// the CNF contains an empty clause
+		return Result{Status: Unsatisfiable}
+	}
+
+	if !ls.search(ctx) {
+		return Result{Status: Unknown, Stats: ls.stats}
+	}
+
+	model := make(operators.Model, len(ls.variables))
+	for i, v := range ls.variables {
+		model[v] = ls.assigns[i]
+	}
+
+	return Result{
+		Status: Satisfiable,
+		Model:  model,
+		Stats:  ls.stats,
+	}
+}
+
+// localSearch keeps the current assignment, and for every clause the number of true literals.
+// Clauses use the literal encoding of the Solver.
+type localSearch struct {
+	variables []operators.Variable
+	clauses   [][]literal
+	occurs    [][]int
+
+	assigns  []bool
+	numTrue  []int
+	unsat    []int
+	position []int
+
+	algorithm LocalSearchAlgorithm
+	noise     float64
+	budget    int
+	rng       *rand.Rand
+	stats     Stats
+
+	// scratch space for the candidates of a flip
+	breaks []int
+	scores []float64
+}
+
+// newLocalSearch converts cnf to its internal representation. It returns false if cnf contains a clause that is
+// always false.
+func newLocalSearch(cnf operators.CNF, opts LocalSearchOptions) (*localSearch, bool) {
+	ls := &localSearch{
+		algorithm: opts.Algorithm,
+		noise:     opts.Noise,
+		budget:    opts.FlipBudget,
+		rng:       rand.New(rand.NewSource(opts.Seed)),
+	}
+	if ls.noise == 0 {
+		ls.noise = defaultNoise[opts.Algorithm]
+	}
+
+	index := make(map[interface{}]int)
+
+	for _, cnfClause := range cnf {
+		lits := make([]literal, 0, cnfClause.NumTerms())
+		satisfied := false
+
+		for _, t := range cnfClause.Terms() {
+			negative := false
+			for {
+				if neg, ok := t.(*operators.Negation); ok {
+					negative = !negative
+					t = neg.Negate()
+				} else {
+					break
+				}
+			}
+
+			if c, ok := t.(operators.Constant); ok {
+				satisfied = satisfied || c.Value() != negative
+				continue
+			}
+
+			v := t.Variable()
+			i, ok := index[operators.VariableKey(v)]
+			if !ok {
+				i = len(ls.variables)
+				index[operators.VariableKey(v)] = i
+				ls.variables = append(ls.variables, v)
+				ls.occurs = append(ls.occurs, nil, nil)
+			}
+
+			lit := makeLiteral(i, negative)
+			duplicate := false
+			for _, l := range lits {
+				satisfied = satisfied || l == lit.negate()
+				duplicate = duplicate || l == lit
+			}
+			if !duplicate {
+				lits = append(lits, lit)
+			}
+		}
+
+		if satisfied {
+			continue
+		}
+		if len(lits) == 0 {
+			return nil, false
+		}
+
+		for _, l := range lits {
+			ls.occurs[l] = append(ls.occurs[l], len(ls.clauses))
+		}
+		ls.clauses = append(ls.clauses, lits)
+	}
+
+	ls.assigns = make([]bool, len(ls.variables))
+	for i := range ls.assigns {
+		ls.assigns[i] = ls.rng.Intn(2) == 0
+	}
+
+	ls.numTrue = make([]int, len(ls.clauses))
+	ls.position = make([]int, len(ls.clauses))
+	for c, lits := range ls.clauses {
+		for _, l := range lits {
+			if ls.isTrue(l) {
+				ls.numTrue[c]++
+			}
+		}
+		if ls.numTrue[c] == 0 {
+			ls.position[c] = len(ls.unsat)
+			ls.unsat = append(ls.unsat, c)
+		}
+	}
+
+	return ls, true
+}
+
+func (ls *localSearch) isTrue(l literal) bool {
+	return ls.assigns[l.variable()] != l.negative()
+}
+
+// search flips variables until every clause is satisfied, and returns false if it is stopped first
+func (ls *localSearch) search(ctx context.Context) bool {
+	done := ctx.Done()
+
+	for len(ls.unsat) > 0 {
+		if ls.budget > 0 && ls.stats.Flips >= ls.budget {
+			return false
+		}
+
+		if ls.stats.Flips%1024 == 0 {
+			select {
+			case <-done:
+				return false
+			default:
+			}
+		}
+
+		c := ls.unsat[ls.rng.Intn(len(ls.unsat))]
+
+		var v int
+		if ls.algorithm == ProbSAT {
+			v = ls.pickProbSAT(ls.clauses[c])
+		} else {
+			v = ls.pickWalkSAT(ls.clauses[c])
+		}
+
+		ls.flip(v)
+		ls.stats.Flips++
+	}
+
+	return true
+}
+
+// breakCount returns the number of clauses that become falsified by flipping v
+func (ls *localSearch) breakCount(v int) int {
+	// the literal of v that is currently true
+	lit := makeLiteral(v, !ls.assigns[v])
+
+	count := 0
+	for _, c := range ls.occurs[lit] {
+		if ls.numTrue[c] == 1 {
+			count++
+		}
+	}
+	return count
+}
+
+func (ls *localSearch) pickWalkSAT(lits []literal) int {
+	ls.breaks = ls.breaks[:0]
+	best := math.MaxInt32
+	for _, l := range lits {
+		b := ls.breakCount(l.variable())
+		ls.breaks = append(ls.breaks, b)
+		if b < best {
+			best = b
+		}
+	}
+
+	// a random walk step is only taken if every flip breaks a clause
+	if best > 0 && ls.rng.Float64() < ls.noise {
+		return lits[ls.rng.Intn(len(lits))].variable()
+	}
+
+	// choose uniformly between the variables with the fewest breaks
+	candidates := 0
+	v := -1
+	for i, b := range ls.breaks {
+		if b == best {
+			if candidates++; ls.rng.Intn(candidates) == 0 {
+				v = lits[i].variable()
+			}
+		}
+	}
+	return v
+}
+
+func (ls *localSearch) pickProbSAT(lits []literal) int {
+	ls.scores = ls.scores[:0]
+	total := 0.0
+	for _, l := range lits {
+		score := math.Pow(probSATEpsilon+float64(ls.breakCount(l.variable())), -ls.noise)
+		ls.scores = append(ls.scores, score)
+		total += score
+	}
+
+	r := ls.rng.Float64() * total
+	for i, score := range ls.scores {
+		if r -= score; r <= 0 {
+			return lits[i].variable()
+		}
+	}
+	return lits[len(lits)-1].variable()
+}
+
+// flip negates the value of v, and updates the number of true literals and the falsified clauses
+func (ls *localSearch) flip(v int) {
+	ls.assigns[v] = !ls.assigns[v]
+
+	// the literal of v that became true, and the literal that became false
+	lit := makeLiteral(v, !ls.assigns[v])
+
+	for _, c := range ls.occurs[lit] {
+		if ls.numTrue[c]++; ls.numTrue[c] == 1 {
+			// remove c from the falsified clauses
+			last := ls.unsat[len(ls.unsat)-1]
+			ls.unsat[ls.position[c]] = last
+			ls.position[last] = ls.position[c]
+			ls.unsat = ls.unsat[:len(ls.unsat)-1]
+		}
+	}
+
+	for _, c := range ls.occurs[lit.negate()] {
+		if ls.numTrue[c]--; ls.numTrue[c] == 0 {
+			ls.position[c] = len(ls.unsat)
+			ls.unsat = append(ls.unsat, c)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package algorithm
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// plantedCNF returns a random k-CNF that is satisfied by a hidden random assignment
+func plantedCNF(seed int64, numVariables, numClauses, k int) operators.CNF {
+	r := rand.New(rand.NewSource(seed))
+
+	variables := make([]operators.Variable, numVariables)
+	hidden := make([]bool, numVariables)
+	for i := range variables {
+		variables[i] = operators.IVar(i + 1)
+		hidden[i] = r.Intn(2) == 0
+	}
+
+	cnf := make(operators.CNF, 0, numClauses)
+	for len(cnf) < numClauses {
+		clause := make(operators.NClause, k)
+		satisfied := false
+		for j := range clause {
+			i := r.Intn(numVariables)
+			negative := r.Intn(2) == 0
+			satisfied = satisfied || hidden[i] != negative
+			if negative {
+				clause[j] = variables[i].Negate()
+			} else {
+				clause[j] = variables[i]
+			}
+		}
+		if satisfied {
+			cnf = append(cnf, clause)
+		}
+	}
+
+	return cnf
+}
+
+func TestLocalSearch(t *testing.T) {
+	instances := map[string]operators.CNF{
+		"uf20-91":           readDIMACS(t, "testdata/uf20-91.cnf"),
+		"planted 3-sat 500": plantedCNF(1, 500, 2000, 3),
+	}
+
+	algorithms := map[string]LocalSearchAlgorithm{
+		"walksat": WalkSAT,
+		"probsat": ProbSAT,
+	}
+
+	for name, cnf := range instances {
+		for algorithmName, algorithm := range algorithms {
+			be := bdd_test.Bench{T: t}
+
+			result := LocalSearch(cnf, LocalSearchOptions{Algorithm: algorithm, FlipBudget: 1000000, Seed: 42})
+			be.AssertInfo(fmt.Sprintf("%s finds a model of %s", algorithmName, name), result.Sat(), result.Stats)
+			be.Assert(fmt.Sprintf("model of %s satisfies the clauses", name), satisfies(cnf, result.Model))
+		}
+	}
+}
+
+func TestLocalSearchSeed(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	cnf := plantedCNF(2, 200, 800, 3)
+
+	first := LocalSearch(cnf, LocalSearchOptions{Algorithm: ProbSAT, Seed: 7})
+	second := LocalSearch(cnf, LocalSearchOptions{Algorithm: ProbSAT, Seed: 7})
+
+	be.Assert("search is sat", first.Sat() && second.Sat())
+	be.AssertInfo("the same seed repeats the search", first.Stats.Flips == second.Stats.Flips, first.Stats, second.Stats)
+}
+
+func TestLocalSearchIncomplete(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	result := LocalSearch(pigeonhole(4), LocalSearchOptions{FlipBudget: 5000})
+	be.AssertInfo("local search cannot show that pigeonhole(4) is unsat", result.Status == Unknown, result.Status)
+	be.AssertInfo("the search stops at the flip budget", result.Stats.Flips == 5000, result.Stats)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result = LocalSearchContext(ctx, pigeonhole(4), LocalSearchOptions{})
+	be.AssertInfo("a cancelled search is unknown", result.Status == Unknown, result.Status)
+
+	result = LocalSearch(operators.CNF{operators.NClause{operators.Cons(false)}}, LocalSearchOptions{})
+	be.AssertInfo("a cnf with an empty clause is unsat", result.Status == Unsatisfiable, result.Status)
+
+	a := operators.Var("a")
+	result = LocalSearch(operators.CNF{operators.NClause{a, a.Negate()}, operators.NClause{operators.Cons(true)}}, LocalSearchOptions{})
+	be.AssertInfo("tautologies are satisfied", result.Sat(), result.Status)
+}
@@ -0,0 +1,172 @@
+package algorithm
+
+import (
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// softInstance is a soft clause in the solver, which is enabled by assuming its selector
+type softInstance struct {
+	clause   operators.NClause
+	weight   int
+	selector operators.Term
+}
+
+// MaxSAT searches for an assignment that satisfies every hard clause, and minimizes the total weight of the
+// falsified soft clauses. Soft clauses with a weight of zero or less are ignored.
+// If the hard clauses are unsatisfiable, the status of the result is Unsatisfiable. Otherwise, the model of the
+// result is optimal, and cost is the total weight of the soft clauses it falsifies.
+//
+// MaxSAT implements the core-guided WPM1 algorithm (weighted Fu-Malik) on top of the incremental Solver:
+// every unsatisfiable core of soft clauses is relaxed by fresh variables, of which exactly one may be true.
+func MaxSAT(hard operators.CNF, soft []operators.SoftClause) (result Result, cost int) {
+	s := NewSolver()
+	if !s.AddCNF(hard) {
+		return Result{Status: Unsatisfiable, Stats: s.Stats()}, 0
+	}
+
+	var instances []softInstance
+	for _, sc := range soft {
+		if sc.Weight > 0 {
+			clause := append(operators.NClause(nil), sc.Clause.Terms()...)
+			instances = append(instances, softInstance{clause: clause, weight: sc.Weight})
+		}
+	}
+
+	enable := func(instance softInstance) softInstance {
+		instance.selector = operators.IncVar()
+		s.AddClause(append(instance.clause[:len(instance.clause):len(instance.clause)], instance.selector.Negate()))
+		return instance
+	}
+
+	for i := range instances {
+		instances[i] = enable(instances[i])
+	}
+
+	for {
+		assumptions := make([]operators.Term, len(instances))
+		selected := make(map[operators.Term]int, len(instances))
+		for i, instance := range instances {
+			assumptions[i] = instance.selector
+			selected[instance.selector] = i
+		}
+
+		if s.Solve(assumptions...) {
+			break
+		}
+
+		core := s.Core()
+		if len(core) == 0 {
+			// the hard clauses are unsatisfiable
+			return Result{Status: Unsatisfiable, Stats: s.Stats()}, 0
+		}
+
+		minimum := 0
+		for _, t := range core {
+			if w := instances[selected[t]].weight; minimum == 0 || w < minimum {
+				minimum = w
+			}
+		}
+
+		// relax every soft clause in the core: the part of the weight above the minimum remains as it is,
+		// a copy with the minimum weight is extended with a relaxation variable
+		relaxed := make(map[int]bool, len(core))
+		relaxation := make([]operators.Term, 0, len(core))
+		for _, t := range core {
+			i := selected[t]
+			if relaxed[i] {
+				continue
+			}
+			relaxed[i] = true
+
+			instance := instances[i]
+			s.AddClause(operators.NClause{instance.selector.Negate()})
+
+			r := operators.IncVar()
+			relaxation = append(relaxation, r)
+
+			copied := softInstance{
+				clause: append(instance.clause[:len(instance.clause):len(instance.clause)], r),
+				weight: minimum,
+			}
+			instances = append(instances, enable(copied))
+
+			if instance.weight > minimum {
+				instance.weight -= minimum
+				instances[i] = enable(instance)
+			} else {
+				instances[i].weight = 0
+			}
+		}
+
+		// exactly one relaxation variable is true
+		s.AddClause(operators.NClause(relaxation))
+		for i := range relaxation {
+			for j := i + 1; j < len(relaxation); j++ {
+				s.AddClause(operators.NClause{relaxation[i].Negate(), relaxation[j].Negate()})
+			}
+		}
+
+		// remove the soft clauses that were relaxed completely
+		remaining := instances[:0]
+		for _, instance := range instances {
+			if instance.weight > 0 {
+				remaining = append(remaining, instance)
+			}
+		}
+		instances = remaining
+	}
+
+	// restrict the model to the variables of the instance
+	keys := make(map[interface{}]bool)
+	for _, t := range operators.Variables(hard) {
+		keys[operators.VariableKey(t.Variable())] = true
+	}
+	for _, sc := range soft {
+		for _, t := range sc.Clause.Terms() {
+			if _, ok := t.(operators.Constant); !ok {
+				keys[operators.VariableKey(t.Variable())] = true
+			}
+		}
+	}
+
+	model := make(operators.Model, len(keys))
+	values := make(map[interface{}]bool, len(keys))
+	for v, value := range s.Model() {
+		if key := operators.VariableKey(v); keys[key] {
+			model[v] = value
+			values[key] = value
+		}
+	}
+
+	for _, sc := range soft {
+		if sc.Weight > 0 && !softSatisfied(sc.Clause, values) {
+			cost += sc.Weight
+		}
+	}
+
+	return Result{Status: Satisfiable, Model: model, Stats: s.Stats()}, cost
+}
+
+// softSatisfied returns true iff a term of the clause is true, where values are indexed by VariableKey
+func softSatisfied(clause operators.CNFClause, values map[interface{}]bool) bool {
+	for _, t := range clause.Terms() {
+		negative := false
+		for {
+			if neg, ok := t.(*operators.Negation); ok {
+				negative = !negative
+				t = neg.Negate()
+			} else {
+				break
+			}
+		}
+
+		if c, ok := t.(operators.Constant); ok {
+			if c.Value() != negative {
+				return true
+			}
+		} else if values[operators.VariableKey(t.Variable())] != negative {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package algorithm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+func TestMaxSAT(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c := operators.Var("a"), operators.Var("b"), operators.Var("c")
+
+	hard := operators.CNF{
+		operators.NClause{a, b},
+		operators.NClause{a.Negate(), c},
+	}
+
+	soft := []operators.SoftClause{
+		{Clause: a.Negate(), Weight: 3},
+		{Clause: b.Negate(), Weight: 2},
+		{Clause: c.Negate(), Weight: 4},
+		{Clause: a, Weight: 0},
+	}
+
+	result, cost := MaxSAT(hard, soft)
+	be.Assert("hard clauses are sat", result.Sat())
+	be.AssertInfo("model satisfies the hard clauses", satisfies(hard, result.Model), result.Model)
+	be.AssertInfo("falsifying not b is cheapest", cost == 2, cost, result.Model)
+	be.AssertInfo("b is true in the optimal model", !result.Model[a] && result.Model[b] && !result.Model[c], result.Model)
+	be.AssertInfo("model is restricted to the variables of the instance", len(result.Model) == 3, result.Model)
+}
+
+func TestMaxSATUnsat(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a := operators.Var("a")
+
+	result, cost := MaxSAT(pigeonhole(3), []operators.SoftClause{{Clause: a, Weight: 1}})
+	be.Assert("pigeonhole is unsat", result.Status == Unsatisfiable)
+	be.Assert("unsat instances have no cost", cost == 0)
+}
+
+func TestMaxSATPigeonhole(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	// every clause of the pigeonhole formula is soft: all but one are satisfiable
+	var soft []operators.SoftClause
+	for _, clause := range pigeonhole(4) {
+		soft = append(soft, operators.SoftClause{Clause: clause, Weight: 1})
+	}
+
+	result, cost := MaxSAT(nil, soft)
+	be.Assert("without hard clauses, the instance is sat", result.Sat())
+	be.AssertInfo("one clause of the pigeonhole formula is falsified", cost == 1, cost)
+}
+
+func TestMaxSATWCNF(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	input := "p wcnf 3 6 100\n100 1 2 3 0\n100 -1 -2 0\n5 -3 0\n3 1 0\n3 2 0\n4 -1 3 0\n"
+
+	hard, soft, err := operators.ParseWCNF(strings.NewReader(input))
+	be.Assert("wcnf is parsed without errors", err == nil)
+
+	result, cost := MaxSAT(hard, soft)
+	be.Assert("hard clauses are sat", result.Sat())
+	be.AssertInfo("model satisfies the hard clauses", satisfies(hard, result.Model), result.Model)
+	be.AssertInfo("optimal cost is 3", cost == 3, cost, result.Model)
+}
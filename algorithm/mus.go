@@ -0,0 +1,182 @@
+package algorithm
+
+import (
+	"sort"
+
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// Group is a set of clauses with a label, e.g. the clauses of the Tseitin transformation of one user-level constraint.
+// MUS and EnumerateMCS report conflicts in terms of groups, such that a conflict can be explained by labels instead
+// of auxiliary clauses.
+type Group struct {
+	Label   string
+	Clauses operators.CNF
+}
+
+// NewGroup labels the clauses of the Tseitin transformation of e
+func NewGroup(label string, e operators.Expression) Group {
+	return Group{Label: label, Clauses: TransformTseitin(e)}
+}
+
+// groupSolver adds every group to a solver, guarded by a fresh selector variable: the clauses of a group only hold if
+// its selector is true
+type groupSolver struct {
+	s         *Solver
+	selectors []operators.Term
+	index     map[operators.Term]int
+}
+
+func newGroupSolver(hard operators.CNF, groups []Group) *groupSolver {
+	g := &groupSolver{
+		s:         NewSolver(),
+		selectors: make([]operators.Term, len(groups)),
+		index:     make(map[operators.Term]int, len(groups)),
+	}
+
+	g.s.AddCNF(hard)
+
+	for i, group := range groups {
+		g.selectors[i] = operators.IncVar()
+		g.index[g.selectors[i]] = i
+
+		for _, clause := range group.Clauses {
+			terms := make(operators.NClause, 0, clause.NumTerms()+1)
+			terms = append(terms, clause.Terms()...)
+			terms = append(terms, g.selectors[i].Negate())
+
+			g.s.AddClause(terms)
+		}
+	}
+
+	return g
+}
+
+// solve solves the hard clauses together with the given groups. If they are unsatisfiable, the groups in the core
+// are returned in ascending order.
+func (g *groupSolver) solve(groups []int) (sat bool, core []int) {
+	assumptions := make([]operators.Term, len(groups))
+	for i, group := range groups {
+		assumptions[i] = g.selectors[group]
+	}
+
+	if g.s.Solve(assumptions...) {
+		return true, nil
+	}
+
+	for _, selector := range g.s.Core() {
+		core = append(core, g.index[selector])
+	}
+	sort.Ints(core)
+
+	return false, core
+}
+
+// labels returns the labels of the given groups
+func labels(groups []Group, indices []int) []string {
+	result := make([]string, len(indices))
+	for i, index := range indices {
+		result[i] = groups[index].Label
+	}
+	return result
+}
+
+// MUS returns the labels of a minimal unsatisfiable subset of the groups: the hard clauses together with these groups
+// are unsatisfiable, but removing any one of the groups makes them satisfiable.
+// The subset is found by deletion: starting from the core of all groups, every group is removed in turn, and kept
+// only if the remaining groups are satisfiable without it. If they are not, the candidates are refined to the core
+// of that call. If the groups are satisfiable, sat is true and the subset is nil. If the hard clauses are
+// unsatisfiable on their own, the subset is empty.
+func MUS(hard operators.CNF, groups []Group) (mus []string, sat bool) {
+	g := newGroupSolver(hard, groups)
+
+	all := make([]int, len(groups))
+	for i := range all {
+		all[i] = i
+	}
+
+	sat, candidates := g.solve(all)
+	if sat {
+		return nil, true
+	}
+
+	// critical groups are part of the subset, the other candidates are yet to be tested
+	var critical []int
+	for len(candidates) > 0 {
+		group := candidates[0]
+		candidates = candidates[1:]
+
+		sat, core := g.solve(append(append([]int(nil), critical...), candidates...))
+		if sat {
+			critical = append(critical, group)
+			continue
+		}
+
+		// the candidates that are not in the core can be removed as well
+		inCore := make(map[int]bool, len(core))
+		for _, c := range core {
+			inCore[c] = true
+		}
+		refined := candidates[:0]
+		for _, c := range candidates {
+			if inCore[c] {
+				refined = append(refined, c)
+			}
+		}
+		candidates = refined
+	}
+
+	sort.Ints(critical)
+	return labels(groups, critical), false
+}
+
+// EnumerateMCS calls fn with the labels of every minimal correction subset of the groups, until fn returns false.
+// A correction subset is a set of groups whose removal makes the hard clauses together with the remaining groups
+// satisfiable, and it is minimal if no group can be kept. Every MCS is the complement of a maximal satisfiable subset,
+// which is found by growing a satisfiable subset one group at a time. A blocking clause requires that every next
+// subset keeps at least one group of every MCS that is found, such that no MCS is reported twice.
+// If the groups are satisfiable together, the only MCS is empty. If the hard clauses are unsatisfiable, there is none.
+func EnumerateMCS(hard operators.CNF, groups []Group, fn func(mcs []string) bool) {
+	g := newGroupSolver(hard, groups)
+
+	for g.s.Solve() {
+		// the groups with a true selector are satisfied together
+		satisfied := make([]bool, len(groups))
+		keep := func() {
+			for i, selector := range g.selectors {
+				satisfied[i] = satisfied[i] || g.s.modelValue(selector.Variable())
+			}
+		}
+		keep()
+
+		for i := range groups {
+			if satisfied[i] {
+				continue
+			}
+
+			grown := []int{i}
+			for j := range groups {
+				if satisfied[j] {
+					grown = append(grown, j)
+				}
+			}
+
+			if sat, _ := g.solve(grown); sat {
+				keep()
+			}
+		}
+
+		var mcs []int
+		blocking := operators.NClause{}
+		for i, kept := range satisfied {
+			if !kept {
+				mcs = append(mcs, i)
+				blocking = append(blocking, g.selectors[i])
+			}
+		}
+
+		if !fn(labels(groups, mcs)) || len(mcs) == 0 || !g.s.AddClause(blocking) {
+			return
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package algorithm
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// musGroups returns labeled constraints with the minimal unsatisfiable subsets {a, not a} and {a, a -> b, not b}
+func musGroups() []Group {
+	a, b, c := operators.Var("a"), operators.Var("b"), operators.Var("c")
+
+	return []Group{
+		NewGroup("a", a),
+		NewGroup("not a", operators.Not(a)),
+		NewGroup("a -> b", operators.Implies(a, b)),
+		NewGroup("not b", operators.Not(b)),
+		NewGroup("c", c),
+	}
+}
+
+// selectGroups returns the groups with the given labels
+func selectGroups(groups []Group, labels []string) []Group {
+	var result []Group
+	for _, group := range groups {
+		for _, label := range labels {
+			if group.Label == label {
+				result = append(result, group)
+			}
+		}
+	}
+	return result
+}
+
+// groupsSat returns true iff the hard clauses together with the groups are satisfiable
+func groupsSat(hard operators.CNF, groups []Group) bool {
+	cnf := append(operators.CNF(nil), hard...)
+	for _, group := range groups {
+		cnf = append(cnf, group.Clauses...)
+	}
+	return CDCL(cnf).Sat()
+}
+
+func TestMUS(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	groups := musGroups()
+
+	mus, sat := MUS(nil, groups)
+	be.AssertInfo("conflicting groups are unsatisfiable", !sat, mus)
+	be.AssertInfo("mus is one of the minimal unsatisfiable subsets", strings.Join(mus, ", ") == "a, not a" ||
+		strings.Join(mus, ", ") == "a, a -> b, not b", mus)
+	be.Assert("mus is unsatisfiable", !groupsSat(nil, selectGroups(groups, mus)))
+
+	for i := range mus {
+		rest := append(append([]string(nil), mus[:i]...), mus[i+1:]...)
+		be.AssertInfo("mus without one of its groups is satisfiable", groupsSat(nil, selectGroups(groups, rest)), rest)
+	}
+
+	mus, sat = MUS(nil, selectGroups(groups, []string{"a", "a -> b", "not b", "c"}))
+	be.AssertInfo("mus is found through an implication", !sat && strings.Join(mus, ", ") == "a, a -> b, not b", mus)
+
+	mus, sat = MUS(nil, selectGroups(groups, []string{"a", "a -> b", "c"}))
+	be.AssertInfo("satisfiable groups have no mus", sat && mus == nil, mus)
+
+	mus, sat = MUS(pigeonhole(3), groups)
+	be.AssertInfo("unsatisfiable hard clauses have an empty mus", !sat && len(mus) == 0, mus)
+}
+
+func TestMUSClauses(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	cnf := pigeonhole(4)
+	groups := make([]Group, len(cnf))
+	for i, clause := range cnf {
+		groups[i] = Group{Label: clause.(operators.NClause).String(), Clauses: operators.CNF{clause}}
+	}
+
+	mus, sat := MUS(nil, groups)
+	be.AssertInfo("pigeonhole is minimally unsatisfiable", !sat && len(mus) == len(cnf), len(mus), len(cnf))
+}
+
+func TestEnumerateMCS(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	groups := musGroups()
+
+	var found []string
+	EnumerateMCS(nil, groups, func(mcs []string) bool {
+		found = append(found, strings.Join(mcs, ", "))
+		return true
+	})
+	sort.Strings(found)
+
+	expected := []string{"a", "not a, a -> b", "not a, not b"}
+	be.AssertInfo("every minimal correction subset is found once", strings.Join(found, "; ") == strings.Join(expected, "; "), found)
+
+	count := 0
+	EnumerateMCS(nil, groups, func(mcs []string) bool {
+		count++
+		return false
+	})
+	be.AssertInfo("enumeration stops when fn returns false", count == 1, count)
+
+	found = nil
+	EnumerateMCS(nil, selectGroups(groups, []string{"a", "c"}), func(mcs []string) bool {
+		found = append(found, strings.Join(mcs, ", "))
+		return true
+	})
+	be.AssertInfo("satisfiable groups have an empty mcs", len(found) == 1 && found[0] == "", found)
+
+	EnumerateMCS(pigeonhole(3), groups, func(mcs []string) bool {
+		be.Assert("unsatisfiable hard clauses have no mcs", false)
+		return true
+	})
+}
@@ -0,0 +1,269 @@
+package algorithm
+
+import (
+	"context"
+	"sync"
+
+	"github.com/timbeurskens/gobdd/operators"
+	"github.com/timbeurskens/gobdd/operators/bdd"
+)
+
+// Strategy is a solver that can be run in a Portfolio.
+// Solve should return a result with status Unknown as soon as possible after ctx is done.
+type Strategy interface {
+	Solve(ctx context.Context, cnf operators.CNF) Result
+}
+
+// StrategyFunc adapts a function to a Strategy
+type StrategyFunc func(ctx context.Context, cnf operators.CNF) Result
+
+func (f StrategyFunc) Solve(ctx context.Context, cnf operators.CNF) Result {
+	return f(ctx, cnf)
+}
+
+// PortfolioOptions configures Portfolio
+type PortfolioOptions struct {
+	// Strategies are run concurrently, nil selects DefaultStrategies
+	Strategies []Strategy
+	// ShareLength is the maximum length of the learnt clauses that are shared between the CDCL strategies,
+	// zero disables sharing
+	ShareLength int
+}
+
+// Portfolio solves cnf by running differently configured solvers concurrently, each in its own goroutine.
+// The first result that is Satisfiable or Unsatisfiable is returned, after the other solvers are cancelled and have
+// returned. The status of the result is Unknown if every solver gives up, or if ctx is done first.
+func Portfolio(ctx context.Context, cnf operators.CNF, opts PortfolioOptions) Result {
+	strategies := opts.Strategies
+	if strategies == nil {
+		strategies = DefaultStrategies()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var exchange *clauseExchange
+	if opts.ShareLength > 0 {
+		exchange = &clauseExchange{maxLength: opts.ShareLength}
+	}
+
+	results := make(chan Result, len(strategies))
+	var wg sync.WaitGroup
+
+	for _, strategy := range strategies {
+		wg.Add(1)
+		go func(strategy Strategy) {
+			defer wg.Done()
+			if c, ok := strategy.(*cdclStrategy); ok && exchange != nil {
+				results <- c.solve(ctx, cnf, exchange)
+			} else {
+				results <- strategy.Solve(ctx, cnf)
+			}
+		}(strategy)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	result := Result{Status: Unknown}
+	for r := range results {
+		if result.Status == Unknown && r.Status != Unknown {
+			result = r
+			cancel()
+		}
+	}
+
+	return result
+}
+
+// DefaultStrategies returns the strategies of a Portfolio without configuration: four CDCL solvers with different
+// seeds, WalkSAT and ProbSAT, and a BDD for CNFs with at most 20 variables
+func DefaultStrategies() []Strategy {
+	return []Strategy{
+		CDCLStrategy(Options{}),
+		CDCLStrategy(Options{Seed: 1, RandomFrequency: 0.01}),
+		CDCLStrategy(Options{Seed: 2, RandomFrequency: 0.02}),
+		CDCLStrategy(Options{Seed: 3, RandomFrequency: 0.05}),
+		LocalSearchStrategy(LocalSearchOptions{Algorithm: WalkSAT, Seed: 1}),
+		LocalSearchStrategy(LocalSearchOptions{Algorithm: ProbSAT, Seed: 2}),
+		BDDStrategy(20),
+	}
+}
+
+// cdclStrategy runs CDCLContext, and can share its learnt clauses in a Portfolio
+type cdclStrategy struct {
+	opts Options
+}
+
+// CDCLStrategy returns a strategy that solves a CNF by CDCLContext with opts.
+// In a Portfolio, it shares its learnt clauses with the other CDCL strategies.
+func CDCLStrategy(opts Options) Strategy {
+	return &cdclStrategy{opts: opts}
+}
+
+func (c *cdclStrategy) Solve(ctx context.Context, cnf operators.CNF) Result {
+	return c.solve(ctx, cnf, nil)
+}
+
+func (c *cdclStrategy) solve(ctx context.Context, cnf operators.CNF, exchange *clauseExchange) Result {
+	s := newSolverWithOptions(c.opts, cnf)
+	if c.opts.Proof == nil {
+		// clauses learnt by other solvers cannot be justified in the proof of this solver
+		s.exchange = exchange
+	}
+	s.AddCNF(cnf)
+
+	status := s.SolveContext(ctx)
+
+	return Result{
+		Status:   status,
+		Model:    s.Model(),
+		Stats:    s.Stats(),
+		ProofErr: s.ProofErr(),
+	}
+}
+
+// LocalSearchStrategy returns a strategy that searches a model of a CNF by LocalSearchContext with opts
+func LocalSearchStrategy(opts LocalSearchOptions) Strategy {
+	return StrategyFunc(func(ctx context.Context, cnf operators.CNF) Result {
+		return LocalSearchContext(ctx, cnf, opts)
+	})
+}
+
+// boundedVariables returns the variables of cnf, unless it has more than maxVariables variables or ctx is done.
+// The search stops at the first variable beyond the limit, such that large CNFs are rejected quickly.
+func boundedVariables(ctx context.Context, cnf operators.CNF, maxVariables int) ([]operators.Variable, bool) {
+	seen := make(map[interface{}]bool)
+	var variables []operators.Variable
+
+	for _, clause := range cnf {
+		if ctx.Err() != nil {
+			return nil, false
+		}
+		for _, t := range clause.Terms() {
+			v := t.Variable()
+			if v == nil {
+				continue
+			}
+			if key := operators.VariableKey(v); !seen[key] {
+				if len(variables) == maxVariables {
+					return nil, false
+				}
+				seen[key] = true
+				variables = append(variables, v)
+			}
+		}
+	}
+
+	return variables, true
+}
+
+// BDDStrategy returns a strategy that builds the BDD of a CNF, clause by clause, if the CNF has at most maxVariables
+// variables. Otherwise, the BDD would be too large, and the result is Unknown.
+func BDDStrategy(maxVariables int) Strategy {
+	return StrategyFunc(func(ctx context.Context, cnf operators.CNF) Result {
+		variables, ok := boundedVariables(ctx, cnf, maxVariables)
+		if !ok {
+			return Result{Status: Unknown}
+		}
+
+		b := NewBuilder()
+
+		var root operators.Node = operators.Cons(true)
+		for _, clause := range cnf {
+			if ctx.Err() != nil {
+				return Result{Status: Unknown, Stats: b.Stats()}
+			}
+
+			terms := clause.Terms()
+			if len(terms) == 0 {
+				return Result{Status: Unsatisfiable, Stats: b.Stats()}
+			}
+
+			exprs := make([]operators.Expression, len(terms))
+			for i, t := range terms {
+				exprs[i] = t
+			}
+
+			root = b.Apply(root, b.FromExpression(PruneUnary(operators.Or(exprs...))), &operators.Conjunction{})
+		}
+
+		path, ok := bdd.FindModel(root)
+		if !ok {
+			return Result{Status: Unsatisfiable, Stats: b.Stats()}
+		}
+
+		// variables that are not on the path can have any value
+		values := make(map[interface{}]bool, len(path))
+		for v, value := range path {
+			values[operators.VariableKey(v)] = value
+		}
+
+		model := make(operators.Model, len(variables))
+		for _, v := range variables {
+			model[v] = values[operators.VariableKey(v)]
+		}
+
+		return Result{
+			Status: Satisfiable,
+			Model:  model,
+			Stats:  b.Stats(),
+		}
+	})
+}
+
+// clauseExchange collects the short learnt clauses of the solvers in a Portfolio.
+// Clauses are stored as terms, since every solver numbers its variables differently.
+type clauseExchange struct {
+	mu        sync.Mutex
+	maxLength int
+	clauses   []sharedClause
+}
+
+type sharedClause struct {
+	from  *Solver
+	terms operators.NClause
+}
+
+// export shares a learnt clause with the other solvers, if it is short enough.
+// Clauses over auxiliary variables of the solver cannot be shared.
+func (s *Solver) export(lits []literal) {
+	if s.exchange == nil || len(lits) > s.exchange.maxLength {
+		return
+	}
+
+	terms := make(operators.NClause, len(lits))
+	for i, l := range lits {
+		if s.variables[l.variable()] == nil {
+			return
+		}
+		terms[i] = s.term(l)
+	}
+
+	s.exchange.mu.Lock()
+	s.exchange.clauses = append(s.exchange.clauses, sharedClause{from: s, terms: terms})
+	s.exchange.mu.Unlock()
+}
+
+// importClauses adds the clauses that other solvers shared since the last import.
+// It returns false iff the clauses in the solver are known to be unsatisfiable.
+func (s *Solver) importClauses() bool {
+	if s.exchange == nil {
+		return s.ok
+	}
+
+	s.exchange.mu.Lock()
+	shared := s.exchange.clauses[s.exchangeRead:]
+	s.exchangeRead = len(s.exchange.clauses)
+	s.exchange.mu.Unlock()
+
+	for _, c := range shared {
+		if c.from != s && !s.AddClause(c.terms) {
+			return false
+		}
+	}
+
+	return s.ok
+}
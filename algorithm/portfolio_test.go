@@ -0,0 +1,124 @@
+package algorithm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+func TestPortfolio(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	ctx := context.Background()
+
+	cnf := readDIMACS(t, "testdata/uf20-91.cnf")
+	result := Portfolio(ctx, cnf, PortfolioOptions{})
+	be.AssertInfo("uf20-91 is sat", result.Sat(), result.Status)
+	be.Assert("model satisfies the clauses", satisfies(cnf, result.Model))
+
+	result = Portfolio(ctx, pigeonhole(5), PortfolioOptions{ShareLength: 8})
+	be.AssertInfo("pigeonhole(5) is unsat with clause sharing", result.Status == Unsatisfiable, result.Status)
+
+	result = Portfolio(ctx, plantedCNF(3, 1000, 4000, 3), PortfolioOptions{})
+	be.AssertInfo("a large planted instance is sat", result.Sat(), result.Status)
+}
+
+func TestPortfolioStrategies(t *testing.T) {
+	ctx := context.Background()
+
+	strategies := map[string]Strategy{
+		"cdcl":             CDCLStrategy(Options{}),
+		"seeded cdcl":      CDCLStrategy(Options{Seed: 42, RandomFrequency: 0.1}),
+		"bdd":              BDDStrategy(20),
+		"walksat":          LocalSearchStrategy(LocalSearchOptions{Algorithm: WalkSAT}),
+		"probsat":          LocalSearchStrategy(LocalSearchOptions{Algorithm: ProbSAT}),
+		"default strategy": StrategyFunc(func(ctx context.Context, cnf operators.CNF) Result { return CDCLContext(ctx, cnf, Options{}) }),
+	}
+
+	cnf := readDIMACS(t, "testdata/uf20-91.cnf")
+
+	for name, strategy := range strategies {
+		be := bdd_test.Bench{T: t}
+
+		result := strategy.Solve(ctx, cnf)
+		be.AssertInfo(fmt.Sprintf("%s finds a model of uf20-91", name), result.Sat(), result.Status)
+		be.Assert(fmt.Sprintf("model of %s satisfies the clauses", name), satisfies(cnf, result.Model))
+
+		result = Portfolio(ctx, cnf, PortfolioOptions{Strategies: []Strategy{strategy}})
+		be.AssertInfo(fmt.Sprintf("a portfolio of %s finds a model of uf20-91", name), result.Sat(), result.Status)
+	}
+
+	be := bdd_test.Bench{T: t}
+
+	result := BDDStrategy(20).Solve(ctx, pigeonhole(3))
+	be.AssertInfo("bdd shows that pigeonhole(3) is unsat", result.Status == Unsatisfiable, result.Status)
+
+	result = BDDStrategy(10).Solve(ctx, cnf)
+	be.AssertInfo("bdd gives up on more than 10 variables", result.Status == Unknown, result.Status)
+
+	// counting the variables stops at the limit, such that large instances are rejected immediately
+	large := plantedCNF(3, 20000, 60000, 3)
+	start := time.Now()
+	result = BDDStrategy(20).Solve(ctx, large)
+	be.AssertInfo("bdd gives up on a large instance", result.Status == Unknown, result.Status)
+	be.AssertInfo("bdd gives up quickly", time.Since(start) < 100*time.Millisecond, time.Since(start))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	result = BDDStrategy(1<<20).Solve(cancelled, large)
+	be.AssertInfo("bdd is unknown when ctx is done", result.Status == Unknown, result.Status)
+}
+
+func TestPortfolioCancel(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	// local search never finishes on an unsatisfiable cnf
+	strategies := []Strategy{
+		LocalSearchStrategy(LocalSearchOptions{Algorithm: WalkSAT}),
+		LocalSearchStrategy(LocalSearchOptions{Algorithm: ProbSAT}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result := Portfolio(ctx, pigeonhole(4), PortfolioOptions{Strategies: strategies})
+	be.AssertInfo("the portfolio is unknown when ctx is done", result.Status == Unknown, result.Status)
+
+	// the slow strategy is cancelled as soon as the fast strategy finds a model
+	slow := StrategyFunc(func(ctx context.Context, cnf operators.CNF) Result {
+		<-ctx.Done()
+		return Result{Status: Unknown}
+	})
+
+	result = Portfolio(context.Background(), pigeonhole(4), PortfolioOptions{Strategies: []Strategy{slow, CDCLStrategy(Options{})}})
+	be.AssertInfo("the first definitive answer cancels the other strategies", result.Status == Unsatisfiable, result.Status)
+}
+
+func TestIncVarConcurrent(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	const goroutines, count = 8, 1000
+
+	variables := make([][]operators.Term, goroutines)
+	var wg sync.WaitGroup
+	for i := range variables {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			variables[i] = operators.IncVarCollection(count)
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[interface{}]bool)
+	for _, vs := range variables {
+		for _, v := range vs {
+			seen[operators.VariableKey(v.Variable())] = true
+		}
+	}
+	be.AssertInfo("every fresh variable is different", len(seen) == goroutines*count, len(seen))
+}
@@ -0,0 +1,499 @@
+package algorithm
+
+import (
+	"sort"
+
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+const (
+	// eliminationOccurrences is the maximum number of occurrences of both polarities of a variable for it to be
+	// considered for bounded variable elimination
+	eliminationOccurrences = 16
+	// eliminationResolventSize is the maximum size of a resolvent produced by bounded variable elimination
+	eliminationResolventSize = 24
+)
+
+// Preprocessed is the result of Preprocess.
+// CNF is a simplified formula that is satisfiable iff the original formula is satisfiable.
+// A model of CNF can be extended to a model of the original formula with Extend.
+type Preprocessed struct {
+	CNF operators.CNF
+
+	variables []operators.Variable
+	fixed     []literal
+	stack     []eliminatedClause
+}
+
+// eliminatedClause is a clause that was removed by variable elimination.
+// When extending a model, the witness is made true if the clause is not satisfied.
+type eliminatedClause struct {
+	witness literal
+	lits    []literal
+}
+
+// Preprocess simplifies cnf by unit propagation, pure-literal elimination, subsumption, self-subsuming resolution and
+// bounded variable elimination (as in SatELite). Frozen variables are never eliminated, such that they can still be
+// used in assumptions or projections.
+// If cnf is found to be unsatisfiable, the resulting CNF consists of a single empty clause.
+func Preprocess(cnf operators.CNF, frozen ...operators.Variable) *Preprocessed {
+	p := newPreprocessor()
+
+	for _, v := range frozen {
+		p.frozen[p.variable(v)] = true
+	}
+
+	for _, c := range cnf {
+		lits, satisfied := p.literals(c)
+		if !satisfied {
+			p.addClause(lits)
+		}
+	}
+
+	p.run()
+
+	return p.result()
+}
+
+// Extend returns a model of the original formula, given a model of the preprocessed formula.
+// Variables that do not occur in the given model are assumed to be false.
+func (p *Preprocessed) Extend(model operators.Model) operators.Model {
+	values := make(map[interface{}]bool, len(model))
+	for v, value := range model {
+		values[operators.VariableKey(v)] = value
+	}
+
+	assignment := make([]bool, len(p.variables))
+	for i, v := range p.variables {
+		assignment[i] = values[operators.VariableKey(v)]
+	}
+
+	for _, l := range p.fixed {
+		assignment[l.variable()] = !l.negative()
+	}
+
+	for i := len(p.stack) - 1; i >= 0; i-- {
+		if e := p.stack[i]; !satisfiedBy(e.lits, assignment) {
+			assignment[e.witness.variable()] = !e.witness.negative()
+		}
+	}
+
+	result := make(operators.Model, len(p.variables)+len(model))
+	for v, value := range model {
+		result[v] = value
+	}
+	for i, v := range p.variables {
+		result[v] = assignment[i]
+	}
+
+	return result
+}
+
+func satisfiedBy(lits []literal, assignment []bool) bool {
+	for _, l := range lits {
+		if assignment[l.variable()] != l.negative() {
+			return true
+		}
+	}
+	return false
+}
+
+type preprocessor struct {
+	variables []operators.Variable
+	index     map[interface{}]int
+	frozen    []bool
+	values    []lbool
+	marks     []bool
+
+	clauses [][]literal
+	occ     [][]int
+
+	queue []literal
+	dirty []int
+	unsat bool
+
+	fixed []literal
+	stack []eliminatedClause
+}
+
+func newPreprocessor() *preprocessor {
+	return &preprocessor{
+		index: make(map[interface{}]int),
+	}
+}
+
+func (p *preprocessor) variable(v operators.Variable) int {
+	key := operators.VariableKey(v)
+	if i, ok := p.index[key]; ok {
+		return i
+	}
+
+	i := len(p.variables)
+	p.index[key] = i
+	p.variables = append(p.variables, v)
+	p.frozen = append(p.frozen, false)
+	p.values = append(p.values, lUndef)
+	p.marks = append(p.marks, false, false)
+	p.occ = append(p.occ, nil, nil)
+	return i
+}
+
+// literals converts a clause to literals, satisfied is true if the clause contains the constant true
+func (p *preprocessor) literals(c operators.CNFClause) (lits []literal, satisfied bool) {
+	for _, t := range c.Terms() {
+		negative := false
+		for {
+			if neg, ok := t.(*operators.Negation); ok {
+				negative = !negative
+				t = neg.Negate()
+			} else {
+				break
+			}
+		}
+
+		if constant, ok := t.(operators.Constant); ok {
+			if constant.Value() != negative {
+				return nil, true
+			}
+			continue
+		}
+
+		lits = append(lits, makeLiteral(p.variable(t.Variable()), negative))
+	}
+	return lits, false
+}
+
+func (p *preprocessor) value(l literal) lbool {
+	v := p.values[l.variable()]
+	if v == lUndef || !l.negative() {
+		return v
+	}
+	if v == lTrue {
+		return lFalse
+	}
+	return lTrue
+}
+
+// addClause adds a clause to the database, after removing duplicate and falsified literals
+func (p *preprocessor) addClause(lits []literal) {
+	if p.unsat {
+		return
+	}
+
+	sort.Slice(lits, func(i, j int) bool {
+		return lits[i] < lits[j]
+	})
+
+	j := 0
+	for i, l := range lits {
+		if p.value(l) == lTrue || (i > 0 && l == lits[i-1].negate()) {
+			return
+		}
+		if p.value(l) == lUndef && (j == 0 || l != lits[j-1]) {
+			lits[j] = l
+			j++
+		}
+	}
+	lits = lits[:j]
+
+	switch len(lits) {
+	case 0:
+		p.unsat = true
+	case 1:
+		p.assign(lits[0])
+	default:
+		ci := len(p.clauses)
+		p.clauses = append(p.clauses, lits)
+		for _, l := range lits {
+			p.occ[l] = append(p.occ[l], ci)
+		}
+		p.dirty = append(p.dirty, ci)
+	}
+}
+
+func (p *preprocessor) assign(l literal) {
+	switch p.value(l) {
+	case lFalse:
+		p.unsat = true
+	case lUndef:
+		p.values[l.variable()] = liftBool(!l.negative())
+		p.fixed = append(p.fixed, l)
+		p.queue = append(p.queue, l)
+	}
+}
+
+// occurrences returns the clauses that currently contain l, stale entries are removed from the occurrence list
+func (p *preprocessor) occurrences(l literal) []int {
+	j := 0
+	for _, ci := range p.occ[l] {
+		if p.clauses[ci] != nil && hasLiteral(p.clauses[ci], l) {
+			p.occ[l][j] = ci
+			j++
+		}
+	}
+	p.occ[l] = p.occ[l][:j]
+	return p.occ[l]
+}
+
+func (p *preprocessor) removeClause(ci int) {
+	p.clauses[ci] = nil
+}
+
+// strengthen removes l from clause ci
+func (p *preprocessor) strengthen(ci int, l literal) {
+	c := p.clauses[ci]
+	j := 0
+	for _, q := range c {
+		if q != l {
+			c[j] = q
+			j++
+		}
+	}
+	c = c[:j]
+
+	if len(c) == 1 {
+		p.removeClause(ci)
+		p.assign(c[0])
+	} else {
+		p.clauses[ci] = c
+		p.dirty = append(p.dirty, ci)
+	}
+}
+
+// propagate removes every satisfied clause and falsified literal of the assigned literals in the queue
+func (p *preprocessor) propagate() {
+	for len(p.queue) > 0 && !p.unsat {
+		l := p.queue[0]
+		p.queue = p.queue[1:]
+
+		for _, ci := range p.occurrences(l) {
+			p.removeClause(ci)
+		}
+
+		for _, ci := range append([]int(nil), p.occurrences(l.negate())...) {
+			if p.clauses[ci] != nil {
+				p.strengthen(ci, l.negate())
+			}
+		}
+	}
+}
+
+// subsumes returns true iff every literal of c is in d, where flip (if defined) is negated
+func (p *preprocessor) subsumes(c, d []literal, flip literal) bool {
+	for _, l := range d {
+		p.marks[l] = true
+	}
+
+	result := true
+	for _, l := range c {
+		if l == flip {
+			l = l.negate()
+		}
+		if !p.marks[l] {
+			result = false
+			break
+		}
+	}
+
+	for _, l := range d {
+		p.marks[l] = false
+	}
+
+	return result
+}
+
+// subsume removes every clause subsumed by clause ci, and strengthens every clause with which ci can be resolved
+// such that the resolvent subsumes the other clause (self-subsuming resolution)
+func (p *preprocessor) subsume(ci int) {
+	c := p.clauses[ci]
+
+	// subsumption: every subsumed clause contains the literal of c with the fewest occurrences
+	best := c[0]
+	for _, l := range c[1:] {
+		if len(p.occ[l]) < len(p.occ[best]) {
+			best = l
+		}
+	}
+
+	for _, di := range p.occurrences(best) {
+		if d := p.clauses[di]; di != ci && len(d) >= len(c) && p.subsumes(c, d, undefLiteral) {
+			p.removeClause(di)
+		}
+	}
+
+	// self-subsuming resolution on every literal of c
+	for _, l := range c {
+		for _, di := range append([]int(nil), p.occurrences(l.negate())...) {
+			if d := p.clauses[di]; d != nil && di != ci && len(d) >= len(c) && p.subsumes(c, d, l) {
+				p.strengthen(di, l.negate())
+			}
+		}
+
+		if p.clauses[ci] == nil {
+			return
+		}
+	}
+}
+
+// simplify applies unit propagation and subsumption until a fixpoint is reached
+func (p *preprocessor) simplify() {
+	for !p.unsat && (len(p.queue) > 0 || len(p.dirty) > 0) {
+		p.propagate()
+
+		for len(p.dirty) > 0 && len(p.queue) == 0 && !p.unsat {
+			ci := p.dirty[len(p.dirty)-1]
+			p.dirty = p.dirty[:len(p.dirty)-1]
+
+			if p.clauses[ci] != nil {
+				p.subsume(ci)
+			}
+		}
+	}
+}
+
+// eliminate removes variable v by resolution if this does not increase the number of clauses.
+// Pure literals are eliminated as well, since they do not produce any resolvents.
+func (p *preprocessor) eliminate(v int) bool {
+	if p.frozen[v] || p.values[v] != lUndef {
+		return false
+	}
+
+	positive, negative := makeLiteral(v, false), makeLiteral(v, true)
+	pos, neg := p.occurrences(positive), p.occurrences(negative)
+
+	if len(pos) == 0 && len(neg) == 0 {
+		return false
+	}
+
+	if len(pos) > eliminationOccurrences && len(neg) > eliminationOccurrences {
+		return false
+	}
+
+	resolvents := make([][]literal, 0, len(pos)+len(neg))
+	for _, pi := range pos {
+		for _, ni := range neg {
+			if r, ok := p.resolve(p.clauses[pi], p.clauses[ni], v); ok {
+				if len(r) > eliminationResolventSize || len(resolvents) == len(pos)+len(neg) {
+					return false
+				}
+				resolvents = append(resolvents, r)
+			}
+		}
+	}
+
+	for _, pi := range pos {
+		p.stack = append(p.stack, eliminatedClause{positive, p.clauses[pi]})
+		p.removeClause(pi)
+	}
+
+	for _, ni := range neg {
+		p.stack = append(p.stack, eliminatedClause{negative, p.clauses[ni]})
+		p.removeClause(ni)
+	}
+
+	for _, r := range resolvents {
+		p.addClause(r)
+	}
+
+	return true
+}
+
+// resolve returns the resolvent of a and b on variable v, ok is false if the resolvent is a tautology
+func (p *preprocessor) resolve(a, b []literal, v int) (resolvent []literal, ok bool) {
+	resolvent = make([]literal, 0, len(a)+len(b)-2)
+
+	for _, l := range a {
+		if l.variable() != v {
+			p.marks[l] = true
+			resolvent = append(resolvent, l)
+		}
+	}
+
+	ok = true
+	for _, l := range b {
+		if l.variable() == v || p.marks[l] {
+			continue
+		}
+		if p.marks[l.negate()] {
+			ok = false
+			break
+		}
+		resolvent = append(resolvent, l)
+	}
+
+	for _, l := range a {
+		p.marks[l] = false
+	}
+
+	return resolvent, ok
+}
+
+func (p *preprocessor) run() {
+	p.simplify()
+
+	for changed := true; changed && !p.unsat; {
+		changed = false
+
+		// try to eliminate variables with few occurrences first
+		order := make([]int, 0, len(p.variables))
+		for v := range p.variables {
+			order = append(order, v)
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return len(p.occ[2*order[i]])*len(p.occ[2*order[i]+1]) < len(p.occ[2*order[j]])*len(p.occ[2*order[j]+1])
+		})
+
+		for _, v := range order {
+			if p.unsat {
+				break
+			}
+			if p.eliminate(v) {
+				changed = true
+				p.simplify()
+			}
+		}
+	}
+}
+
+func (p *preprocessor) result() *Preprocessed {
+	result := &Preprocessed{
+		variables: p.variables,
+		fixed:     p.fixed,
+		stack:     p.stack,
+	}
+
+	if p.unsat {
+		result.CNF = operators.CNF{operators.NClause{}}
+		return result
+	}
+
+	result.CNF = make(operators.CNF, 0, len(p.clauses))
+
+	// frozen variables keep their value in the resulting formula
+	for _, l := range p.fixed {
+		if p.frozen[l.variable()] {
+			result.CNF = append(result.CNF, p.term(l))
+		}
+	}
+
+	for _, c := range p.clauses {
+		if c == nil {
+			continue
+		}
+
+		clause := make(operators.NClause, len(c))
+		for i, l := range c {
+			clause[i] = p.term(l)
+		}
+		result.CNF = append(result.CNF, clause)
+	}
+
+	return result
+}
+
+func (p *preprocessor) term(l literal) operators.Term {
+	if l.negative() {
+		return p.variables[l.variable()].Negate()
+	}
+	return p.variables[l.variable()]
+}
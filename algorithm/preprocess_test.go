@@ -0,0 +1,117 @@
+package algorithm
+
+import (
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// satisfies returns true iff every clause of cnf has a term that is true in model
+func satisfies(cnf operators.CNF, model operators.Model) bool {
+	for _, clause := range cnf {
+		satisfied := false
+		for _, t := range clause.Terms() {
+			if c, ok := t.(operators.Constant); ok {
+				satisfied = c.Value()
+			} else if _, ok := t.(*operators.Negation); ok {
+				satisfied = !model[t.Variable()]
+			} else {
+				satisfied = model[t.Variable()]
+			}
+			if satisfied {
+				break
+			}
+		}
+		if !satisfied {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPreprocess(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c, d, e := operators.Var("a"), operators.Var("b"), operators.Var("c"), operators.Var("d"), operators.Var("e")
+
+	cnf := operators.CNF{
+		operators.NClause{a, b, c},
+		operators.NClause{a, b},
+		operators.NClause{a.Negate(), c, d},
+		operators.NClause{c, d},
+		operators.NClause{b.Negate(), d.Negate(), e},
+		e.Negate(),
+	}
+
+	pre := Preprocess(cnf)
+	be.AssertInfo("preprocessing removes clauses", len(pre.CNF) < len(cnf), pre.CNF)
+
+	result := CDCL(pre.CNF)
+	be.Assert("preprocessed cnf is sat", result.Sat())
+
+	model := pre.Extend(result.Model)
+	be.AssertInfo("extended model satisfies the original cnf", satisfies(cnf, model), model)
+	be.Assert("unit e is false", !model[e])
+}
+
+func TestPreprocessUnsat(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	pre := Preprocess(pigeonhole(4))
+
+	be.AssertInfo("preprocessed pigeonhole is unsat", !CDCL(pre.CNF).Sat(), pre.CNF)
+
+	a := operators.Var("a")
+	pre = Preprocess(operators.CNF{operators.NClause{a, a}, a.Negate()})
+	be.AssertInfo("contradicting units result in the empty clause", len(pre.CNF) == 1 && pre.CNF[0].NumTerms() == 0, pre.CNF)
+}
+
+func TestPreprocessTseitin(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	p, q, r, s := operators.Var("p"), operators.Var("q"), operators.Var("r"), operators.Var("s")
+
+	expr := operators.And(operators.Biimplies(s, q), operators.Or(r, operators.Not(p)))
+	cnf := TransformTseitin(NNF(expr))
+
+	pre := Preprocess(cnf)
+	be.AssertInfo("auxiliary variables are eliminated", len(operators.Variables(pre.CNF)) < len(operators.Variables(cnf)), pre.CNF)
+
+	s2 := NewSolver()
+	s2.AddCNF(pre.CNF)
+	be.Assert("preprocessed cnf is sat", s2.Solve())
+
+	model := pre.Extend(s2.Model())
+	be.AssertInfo("extended model satisfies the original cnf", satisfies(cnf, model), model)
+
+	be.AssertInfo("model is a model of the expression", model[q] == model[s] && (model[r] || !model[p]), model)
+}
+
+func TestPreprocessFrozen(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c := operators.Var("a"), operators.Var("b"), operators.Var("c")
+
+	cnf := operators.CNF{
+		operators.NClause{a.Negate(), b},
+		operators.NClause{b.Negate(), c},
+	}
+
+	pre := Preprocess(cnf, a, c)
+
+	s := NewSolver()
+	s.AddCNF(pre.CNF)
+	be.AssertInfo("frozen variables remain usable as assumptions", !s.Solve(a, c.Negate()), pre.CNF)
+	be.Assert("a implies c", s.Solve(a))
+	be.Assert("extended model satisfies the original cnf", satisfies(cnf, pre.Extend(s.Model())))
+}
+
+func TestPreprocessDIMACS(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	cnf := readDIMACS(t, "testdata/uf20-91.cnf")
+	pre := Preprocess(cnf)
+
+	s := NewSolver()
+	s.AddCNF(pre.CNF)
+	be.Assert("preprocessed cnf is sat", s.Solve())
+	be.Assert("extended model satisfies the original cnf", satisfies(cnf, pre.Extend(s.Model())))
+}
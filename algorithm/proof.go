@@ -0,0 +1,131 @@
+package algorithm
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// ProofFormat selects the encoding of a clausal proof
+type ProofFormat int
+
+const (
+	// DRAT is the textual DRAT format: one clause per line, deletions are prefixed by "d"
+	DRAT ProofFormat = iota
+	// BinaryDRAT is the compact binary DRAT format
+	BinaryDRAT
+)
+
+// proofWriter writes the learnt and deleted clauses of a solver as a DRAT proof.
+// All methods can be called on a nil proofWriter, in which case nothing is written.
+type proofWriter struct {
+	w         *bufio.Writer
+	format    ProofFormat
+	numbering *operators.Numbering
+	numbers   []int
+	buf       []byte
+	err       error
+}
+
+func newProofWriter(w io.Writer, format ProofFormat, numbering *operators.Numbering) *proofWriter {
+	return &proofWriter{
+		w:         bufio.NewWriter(w),
+		format:    format,
+		numbering: numbering,
+	}
+}
+
+// register assigns a number to the next variable of the solver
+func (p *proofWriter) register(v operators.Variable) {
+	if p == nil {
+		return
+	}
+
+	if v == nil {
+		p.numbers = append(p.numbers, p.numbering.Fresh())
+	} else {
+		p.numbers = append(p.numbers, p.numbering.Number(v))
+	}
+}
+
+// dimacs returns the signed number of l in the numbering of the proof
+func (p *proofWriter) dimacs(l literal) int {
+	if l.negative() {
+		return -p.numbers[l.variable()]
+	}
+	return p.numbers[l.variable()]
+}
+
+func (p *proofWriter) add(lits []literal) {
+	p.write(false, lits)
+}
+
+func (p *proofWriter) delete(lits []literal) {
+	p.write(true, lits)
+}
+
+func (p *proofWriter) write(deleted bool, lits []literal) {
+	if p == nil || p.err != nil {
+		return
+	}
+
+	p.buf = p.buf[:0]
+
+	if p.format == BinaryDRAT {
+		if deleted {
+			p.buf = append(p.buf, 'd')
+		} else {
+			p.buf = append(p.buf, 'a')
+		}
+		for _, l := range lits {
+			// variable-length encoding of 2 * variable + sign, 7 bits at a time
+			u := uint(2*p.numbers[l.variable()]) | uint(l&1)
+			for u > 127 {
+				p.buf = append(p.buf, byte(u&127|128))
+				u >>= 7
+			}
+			p.buf = append(p.buf, byte(u))
+		}
+		p.buf = append(p.buf, 0)
+	} else {
+		if deleted {
+			p.buf = append(p.buf, 'd', ' ')
+		}
+		for _, l := range lits {
+			p.buf = strconv.AppendInt(p.buf, int64(p.dimacs(l)), 10)
+			p.buf = append(p.buf, ' ')
+		}
+		p.buf = append(p.buf, '0', '\n')
+	}
+
+	_, p.err = p.w.Write(p.buf)
+}
+
+func (p *proofWriter) flush() {
+	if p == nil || p.err != nil {
+		return
+	}
+	p.err = p.w.Flush()
+}
+
+func (p *proofWriter) error() error {
+	if p == nil {
+		return nil
+	}
+	return p.err
+}
+
+// ProveUnsat solves cnf and writes a DRAT proof of the learnt and deleted clauses to w.
+// If cnf is unsatisfiable, the proof ends with the empty clause and can be verified against the DIMACS file written
+// by operators.WriteDIMACS.
+func ProveUnsat(cnf operators.CNF, w io.Writer, format ProofFormat) (sat bool, err error) {
+	s := NewSolver()
+	s.SetProof(w, format, operators.NewNumbering(cnf))
+	s.AddCNF(cnf)
+
+	sat = s.Solve()
+
+	return sat, s.ProofErr()
+}
@@ -0,0 +1,99 @@
+package algorithm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// pigeonhole constructs the unsatisfiable CNF stating that n+1 pigeons fit in n holes
+func pigeonhole(n int) operators.CNF {
+	p := make([][]operators.Variable, n+1)
+	for i := range p {
+		p[i] = make([]operators.Variable, n)
+		for j := range p[i] {
+			p[i][j] = operators.Var(fmt.Sprintf("p_%d_%d", i, j))
+		}
+	}
+
+	cnf := make(operators.CNF, 0)
+
+	// every pigeon is in a hole
+	for i := range p {
+		clause := make(operators.NClause, n)
+		for j := range p[i] {
+			clause[j] = p[i][j]
+		}
+		cnf = append(cnf, clause)
+	}
+
+	// no two pigeons share a hole
+	for j := 0; j < n; j++ {
+		for i1 := 0; i1 <= n; i1++ {
+			for i2 := i1 + 1; i2 <= n; i2++ {
+				cnf = append(cnf, operators.NClause{p[i1][j].Negate(), p[i2][j].Negate()})
+			}
+		}
+	}
+
+	return cnf
+}
+
+func TestProveUnsat(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	var proof bytes.Buffer
+	sat, err := ProveUnsat(pigeonhole(4), &proof, DRAT)
+
+	be.Assert("pigeonhole is unsat", !sat)
+	be.Assert("proof is written without errors", err == nil)
+
+	lines := strings.Split(strings.TrimSpace(proof.String()), "\n")
+	be.AssertInfo("proof contains learnt clauses", len(lines) > 1, len(lines))
+	be.AssertInfo("proof ends with the empty clause", lines[len(lines)-1] == "0", lines[len(lines)-1])
+}
+
+func TestProveUnsatBinary(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	var proof bytes.Buffer
+	sat, err := ProveUnsat(pigeonhole(4), &proof, BinaryDRAT)
+
+	be.Assert("pigeonhole is unsat", !sat)
+	be.Assert("proof is written without errors", err == nil)
+
+	data := proof.Bytes()
+	be.Assert("proof starts with an addition", len(data) > 0 && data[0] == 'a')
+	be.Assert("proof ends with the empty clause", bytes.HasSuffix(data, []byte{'a', 0}))
+}
+
+func TestProveSat(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b := operators.Var("a"), operators.Var("b")
+
+	var proof bytes.Buffer
+	sat, err := ProveUnsat(operators.CNF{operators.NClause{a, b}}, &proof, DRAT)
+
+	be.Assert("a or b is sat", sat)
+	be.Assert("proof is written without errors", err == nil)
+	be.AssertInfo("proof does not contain the empty clause", !strings.HasSuffix("\n"+proof.String(), "\n0\n"), proof.String())
+}
+
+func TestCDCLContextProof(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	cnf := pigeonhole(4)
+
+	for _, format := range []ProofFormat{DRAT, BinaryDRAT} {
+		var proof bytes.Buffer
+		result := CDCLContext(context.Background(), cnf, Options{Proof: &proof, ProofFormat: format, Preprocess: true})
+
+		be.AssertInfo("pigeonhole is unsat", result.Status == Unsatisfiable, result.Status)
+		be.Assert("proof is written without errors", result.ProofErr == nil)
+		be.AssertInfo("proof is verified against the original cnf", NewProofChecker(cnf).Check(&proof, format) == nil, format)
+	}
+}
@@ -0,0 +1,326 @@
+package algorithm
+
+import (
+	"sort"
+
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// PseudoBooleanEncoding selects the CNF encoding of a pseudo-Boolean constraint
+type PseudoBooleanEncoding int
+
+const (
+	// DecisionDiagram encodes the decision diagram of the constraint, every node by a fresh variable (Eén and Sörensson, 2006)
+	DecisionDiagram PseudoBooleanEncoding = iota
+	// AdderNetwork sums the weighted terms in binary by full adders, and compares the sum to the bound
+	AdderNetwork
+	// SortingNetwork repeats every term as often as its coefficient, and encodes a cardinality network on the repeated terms
+	SortingNetwork
+)
+
+// EncodePseudoBoolean returns clauses that are satisfiable iff the pseudo-Boolean constraint holds.
+// The auxiliary variables of the encoding are fresh variables.
+func EncodePseudoBoolean(p *operators.PseudoBoolean, encoding PseudoBooleanEncoding) operators.CNF {
+	terms, weights, bound := pseudoBooleanTerms(p)
+
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+
+	switch {
+	case bound <= 0:
+		return operators.CNF{}
+	case bound > total:
+		return operators.CNF{operators.NClause{}}
+	}
+
+	switch encoding {
+	case DecisionDiagram:
+		root, cnf := decisionDiagram(terms, weights, bound)
+		return append(cnf, root)
+	case AdderNetwork:
+		root, cnf := adderNetwork(terms, weights, bound)
+		return appendClause(cnf, root)
+	case SortingNetwork:
+		var negated []operators.Term
+		for i, t := range terms {
+			for j := 0; j < weights[i]; j++ {
+				negated = append(negated, t.Negate())
+			}
+		}
+		return encodeAtMost(negated, len(negated)-bound, CardinalityNetwork)
+	default:
+		panic("unrecognized pseudo-boolean encoding")
+	}
+}
+
+// pseudoBooleanTerms normalizes the constraint to Σ weights[i]·terms[i] ≥ bound with positive weights.
+// Constant terms are moved into the bound, terms with a negative coefficient are negated, and weights are saturated
+// at the bound.
+func pseudoBooleanTerms(p *operators.PseudoBoolean) (terms []operators.Term, weights []int, bound int) {
+	bound = p.Bound
+
+	for i, t := range p.Terms {
+		a := p.Coefficients[i]
+
+		if constant, ok := t.(operators.Constant); ok {
+			if constant.Value() {
+				bound -= a
+			}
+			continue
+		}
+
+		switch {
+		case a > 0:
+			terms, weights = append(terms, t), append(weights, a)
+		case a < 0:
+			// a·t = a - a·¬t
+			terms, weights = append(terms, t.Negate()), append(weights, -a)
+			bound -= a
+		}
+	}
+
+	for i := range weights {
+		if bound > 0 && weights[i] > bound {
+			weights[i] = bound
+		}
+	}
+
+	return
+}
+
+// appendClause appends a clause of the terms to cnf, where false constants are removed.
+// The clause is omitted if it contains a true constant.
+func appendClause(cnf operators.CNF, terms ...operators.Term) operators.CNF {
+	clause := make(operators.NClause, 0, len(terms))
+	for _, t := range terms {
+		if constant, ok := t.(operators.Constant); ok {
+			if constant.Value() {
+				return cnf
+			}
+			continue
+		}
+		clause = append(clause, t)
+	}
+	return append(cnf, clause)
+}
+
+// decisionDiagram returns a term that is equivalent to Σ weights[i]·terms[i] ≥ bound, where 0 < bound ≤ Σ weights.
+// Every node of the decision diagram over the terms (in order of decreasing weight) is a fresh variable that is
+// equivalent to its if-then-else, nodes are shared between equal remaining bounds.
+func decisionDiagram(terms []operators.Term, weights []int, bound int) (operators.Term, operators.CNF) {
+	order := make([]int, len(terms))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return weights[order[i]] > weights[order[j]]
+	})
+
+	remaining := make([]int, len(order)+1)
+	for i := len(order) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1] + weights[order[i]]
+	}
+
+	type state struct{ i, bound int }
+	memo := make(map[state]operators.Term)
+
+	var cnf operators.CNF
+
+	var build func(i, bound int) operators.Term
+	build = func(i, bound int) operators.Term {
+		if bound <= 0 {
+			return operators.Cons(true)
+		}
+		if bound > remaining[i] {
+			return operators.Cons(false)
+		}
+		if t, ok := memo[state{i, bound}]; ok {
+			return t
+		}
+
+		t := terms[order[i]]
+		high, low := build(i+1, bound-weights[order[i]]), build(i+1, bound)
+
+		// node ⟷ (t ∧ high) ∨ (¬t ∧ low)
+		node := operators.IncVar()
+		cnf = appendClause(cnf, node.Negate(), t.Negate(), high)
+		cnf = appendClause(cnf, node.Negate(), t, low)
+		cnf = appendClause(cnf, node, t.Negate(), high.Negate())
+		cnf = appendClause(cnf, node, t, low.Negate())
+
+		memo[state{i, bound}] = node
+		return node
+	}
+
+	return build(0, bound), cnf
+}
+
+// adderNetwork returns a term that is equivalent to Σ weights[i]·terms[i] ≥ bound.
+// Every bit of the weights collects the terms in a bucket, which are summed by full and half adders that carry to
+// the bucket of the next bit, after which the binary sum is compared to the bound.
+func adderNetwork(terms []operators.Term, weights []int, bound int) (operators.Term, operators.CNF) {
+	var buckets [][]operators.Term
+	for i, t := range terms {
+		for bit := 0; weights[i]>>bit > 0; bit++ {
+			if weights[i]>>bit&1 == 1 {
+				for len(buckets) <= bit {
+					buckets = append(buckets, nil)
+				}
+				buckets[bit] = append(buckets[bit], t)
+			}
+		}
+	}
+
+	var cnf operators.CNF
+	var sum []operators.Term
+
+	for bit := 0; bit < len(buckets); bit++ {
+		for len(buckets[bit]) > 1 {
+			var s, carry operators.Term
+			if len(buckets[bit]) >= 3 {
+				x, y, z := buckets[bit][0], buckets[bit][1], buckets[bit][2]
+				buckets[bit] = buckets[bit][3:]
+				s, carry = operators.IncVar(), operators.IncVar()
+				cnf = append(cnf, fullAdder(x, y, z, s, carry)...)
+			} else {
+				x, y := buckets[bit][0], buckets[bit][1]
+				buckets[bit] = buckets[bit][2:]
+				s, carry = operators.IncVar(), operators.IncVar()
+				cnf = append(cnf, halfAdder(x, y, s, carry)...)
+			}
+
+			buckets[bit] = append(buckets[bit], s)
+			if bit+1 == len(buckets) {
+				buckets = append(buckets, nil)
+			}
+			buckets[bit+1] = append(buckets[bit+1], carry)
+		}
+
+		if len(buckets[bit]) == 1 {
+			sum = append(sum, buckets[bit][0])
+		} else {
+			sum = append(sum, operators.Cons(false))
+		}
+	}
+
+	// compare from the least significant bit: ge is true iff the lower bits of the sum are at least those of the bound
+	var ge operators.Term = operators.Cons(true)
+	for bit := 0; bit < len(sum) || bound>>bit > 0; bit++ {
+		var s operators.Term = operators.Cons(false)
+		if bit < len(sum) {
+			s = sum[bit]
+		}
+
+		var clauses operators.CNF
+		if bound>>bit&1 == 1 {
+			ge, clauses = andGate(s, ge)
+		} else {
+			ge, clauses = orGate(s, ge)
+		}
+		cnf = append(cnf, clauses...)
+	}
+
+	return ge, cnf
+}
+
+// fullAdder returns the clauses of s ⟷ x ⊗ y ⊗ z and carry ⟷ at least two of x, y and z
+func fullAdder(x, y, z, s, carry operators.Term) operators.CNF {
+	nx, ny, nz, ns, nc := x.Negate(), y.Negate(), z.Negate(), s.Negate(), carry.Negate()
+	return operators.CNF{
+		operators.NClause{nx, ny, nz, s},
+		operators.NClause{nx, y, z, s},
+		operators.NClause{x, ny, z, s},
+		operators.NClause{x, y, nz, s},
+		operators.NClause{x, y, z, ns},
+		operators.NClause{x, ny, nz, ns},
+		operators.NClause{nx, y, nz, ns},
+		operators.NClause{nx, ny, z, ns},
+		operators.NClause{nx, ny, carry},
+		operators.NClause{nx, nz, carry},
+		operators.NClause{ny, nz, carry},
+		operators.NClause{x, y, nc},
+		operators.NClause{x, z, nc},
+		operators.NClause{y, z, nc},
+	}
+}
+
+// halfAdder returns the clauses of s ⟷ x ⊗ y and carry ⟷ x ∧ y
+func halfAdder(x, y, s, carry operators.Term) operators.CNF {
+	nx, ny, ns, nc := x.Negate(), y.Negate(), s.Negate(), carry.Negate()
+	return operators.CNF{
+		operators.NClause{x, y, ns},
+		operators.NClause{nx, ny, ns},
+		operators.NClause{nx, y, s},
+		operators.NClause{x, ny, s},
+		operators.NClause{nx, ny, carry},
+		operators.NClause{x, nc},
+		operators.NClause{y, nc},
+	}
+}
+
+// andGate returns a term that is equivalent to x ∧ y, constants are simplified without a fresh variable
+func andGate(x, y operators.Term) (operators.Term, operators.CNF) {
+	if c, ok := x.(operators.Constant); ok {
+		if c.Value() {
+			return y, nil
+		}
+		return c, nil
+	}
+	if c, ok := y.(operators.Constant); ok {
+		if c.Value() {
+			return x, nil
+		}
+		return c, nil
+	}
+
+	g := operators.IncVar()
+	return g, operators.CNF{
+		operators.NClause{g.Negate(), x},
+		operators.NClause{g.Negate(), y},
+		operators.NClause{g, x.Negate(), y.Negate()},
+	}
+}
+
+// orGate returns a term that is equivalent to x ∨ y, constants are simplified without a fresh variable
+func orGate(x, y operators.Term) (operators.Term, operators.CNF) {
+	g, cnf := andGate(x.Negate(), y.Negate())
+	return g.Negate(), cnf
+}
+
+// reifyPseudoBoolean returns clauses that make v equivalent to the pseudo-Boolean constraint, as used by the
+// Tseitin transformation. The constraint is encoded by its decision diagram.
+func reifyPseudoBoolean(v operators.Term, p *operators.PseudoBoolean) operators.CNF {
+	terms, weights, bound := pseudoBooleanTerms(p)
+
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+
+	switch {
+	case bound <= 0:
+		return operators.CNF{v}
+	case bound > total:
+		return operators.CNF{v.Negate()}
+	}
+
+	root, cnf := decisionDiagram(terms, weights, bound)
+	return append(cnf,
+		operators.NClause{v.Negate(), root},
+		operators.NClause{v, root.Negate()},
+	)
+}
+
+// pseudoBoolean builds the bdd of a pseudo-Boolean constraint directly
+func (b *Builder) pseudoBoolean(p *operators.PseudoBoolean) operators.Node {
+	terms, weights, bound := pseudoBooleanTerms(p)
+
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+
+	return b.linear(terms, weights, bound, total)
+}
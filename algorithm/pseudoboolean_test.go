@@ -0,0 +1,96 @@
+package algorithm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+func TestEncodePseudoBoolean(t *testing.T) {
+	a, b, c, d := operators.Var("a"), operators.Var("b"), operators.Var("c"), operators.Var("d")
+	vars := []operators.Variable{a, b, c, d}
+	terms := []operators.Term{a, b, c.Negate(), d}
+
+	// the number of assignments of a, b, c and d for every bound of 5a + 3b - 2¬c + 4d ≥ k
+	models := map[int]int64{-3: 16, -2: 16, -1: 15, 0: 15, 1: 14, 2: 13, 3: 12, 4: 10, 5: 9, 6: 7, 7: 6, 8: 4, 9: 3, 10: 2, 11: 1, 12: 1, 13: 0}
+
+	encodings := map[string]PseudoBooleanEncoding{
+		"decision diagram": DecisionDiagram,
+		"adder network":    AdderNetwork,
+		"sorting network":  SortingNetwork,
+	}
+
+	for name, encoding := range encodings {
+		be := bdd_test.Bench{T: t}
+
+		for k, expected := range models {
+			p := operators.SumAtLeast(k, []int{5, 3, -2, 4}, terms)
+			count := CountModels(EncodePseudoBoolean(p, encoding), vars)
+			be.AssertInfo(fmt.Sprintf("%s encodes %v", name, p), count.Int64() == expected, count, expected)
+		}
+	}
+}
+
+func TestPseudoBooleanBDD(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c := operators.Var("a"), operators.Var("b"), operators.Var("c")
+
+	// 2a + b + c ≥ 2 iff a or (b and c)
+	direct := FromExpression(operators.SumAtLeast(2, []int{1, 1, 2}, []operators.Term{c, b, a}))
+	expected := FromExpression(operators.Or(a, operators.And(b, c)))
+
+	be.AssertEquivalent("direct bdd is equivalent to a or (b and c)", direct, expected)
+
+	be.AssertUnsat("2a - 2a ≥ 1 is unsatisfiable", FromExpression(operators.SumAtLeast(1, []int{2, -2}, []operators.Term{a, a})))
+	be.AssertTautology("3a + 3¬a ≥ 3 is a tautology", FromExpression(operators.SumAtLeast(3, []int{3, 3}, []operators.Term{a, a.Negate()})))
+}
+
+func TestPseudoBooleanTseitin(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c := operators.Var("a"), operators.Var("b"), operators.Var("c")
+	vars := []operators.Variable{a, b, c}
+	terms := []operators.Term{a, b, c}
+
+	expressions := []struct {
+		name   string
+		expr   operators.Expression
+		models int64
+	}{
+		{"3a + 2b + c ≥ 3", operators.SumAtLeast(3, []int{3, 2, 1}, terms), 5},
+		{"not 3a + 2b + c ≥ 3", operators.Not(operators.SumAtLeast(3, []int{3, 2, 1}, terms)), 3},
+		{"3a + 2b + c = 3", operators.SumEquals(3, []int{3, 2, 1}, terms), 2},
+		{"a xor 2b - c ≤ 0", operators.Xor(a, operators.SumAtMost(0, []int{2, -1}, []operators.Term{b, c})), 4},
+	}
+
+	for _, e := range expressions {
+		count := CountModels(TransformTseitin(NNF(e.expr)), vars)
+		be.AssertInfo(fmt.Sprintf("tseitin transformation of %s has %d models", e.name, e.models), count.Int64() == e.models, count)
+	}
+}
+
+func TestPseudoBooleanOPB(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	// a knapsack: maximize the value 4x1 + 5x2 + 3x3 + 2x4 within weight 5
+	input := "* #variable= 4 #constraint= 2\nmin: -4 x1 -5 x2 -3 x3 -2 x4 ;\n" +
+		"+2 x1 +4 x2 +3 x3 +1 x4 <= 5 ;\n+1 x2 +1 x3 >= 1 ;\n"
+
+	constraints, objective, err := operators.ParseOPB(strings.NewReader(input))
+	be.AssertInfo("opb is parsed without errors", err == nil, err)
+
+	for _, encoding := range []PseudoBooleanEncoding{DecisionDiagram, AdderNetwork, SortingNetwork} {
+		var hard operators.CNF
+		for _, p := range constraints {
+			hard = append(hard, EncodePseudoBoolean(p, encoding)...)
+		}
+
+		soft, offset := objective.SoftClauses()
+		result, cost := MaxSAT(hard, soft)
+
+		be.Assert("knapsack is sat", result.Sat())
+		be.AssertInfo("optimal value is 7 (x1 and x3, or x2 and x4)", offset+cost == -7 && objective.Value(result.Model) == -7, offset+cost, result.Model)
+	}
+}
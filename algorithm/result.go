@@ -0,0 +1,43 @@
+package algorithm
+
+import (
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// Status is the outcome of a satisfiability check
+type Status int
+
+const (
+	// Unknown indicates that satisfiability was not determined
+	Unknown Status = iota
+	// Satisfiable indicates that a model was found
+	Satisfiable
+	// Unsatisfiable indicates that no model exists
+	Unsatisfiable
+)
+
+func (s Status) String() string {
+	switch s {
+	case Satisfiable:
+		return "SAT"
+	case Unsatisfiable:
+		return "UNSAT"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Result is the result of a SAT solver
+type Result struct {
+	Status Status
+	// Model assigns a value to every variable of the CNF if the status is Satisfiable
+	Model operators.Model
+	Stats Stats
+	// ProofErr is the first error that occurred while writing the proof of Options.Proof
+	ProofErr error
+}
+
+// Sat returns true iff the CNF is satisfiable
+func (r Result) Sat() bool {
+	return r.Status == Satisfiable
+}
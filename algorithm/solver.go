@@ -0,0 +1,988 @@
+package algorithm
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// literal is the internal representation of a term in the Solver.
+// A positive occurrence of variable v is encoded as 2v, a negative occurrence as 2v+1.
+type literal int
+
+const undefLiteral literal = -1
+
+func makeLiteral(v int, negative bool) literal {
+	if negative {
+		return literal(2*v + 1)
+	}
+	return literal(2 * v)
+}
+
+func (l literal) variable() int {
+	return int(l >> 1)
+}
+
+func (l literal) negative() bool {
+	return l&1 == 1
+}
+
+func (l literal) negate() literal {
+	return l ^ 1
+}
+
+// lbool is a three-valued boolean: undefined, true or false
+type lbool int8
+
+const (
+	lUndef lbool = iota
+	lTrue
+	lFalse
+)
+
+func liftBool(b bool) lbool {
+	if b {
+		return lTrue
+	}
+	return lFalse
+}
+
+type clause struct {
+	lits     []literal
+	learnt   bool
+	activity float64
+}
+
+type watcher struct {
+	clause  *clause
+	blocker literal
+}
+
+// Solver is an incremental CDCL solver.
+// Clauses can be added in between calls to Solve, and every call to Solve can be given a set of assumptions.
+// If the clauses are unsatisfiable under the assumptions, Core returns the subset of assumptions responsible.
+type Solver struct {
+	variables []operators.Variable
+	index     map[interface{}]int
+
+	clauses []*clause
+	learnts []*clause
+	watches [][]watcher
+	gauss   gaussMatrix
+
+	assigns  []lbool
+	level    []int
+	reason   []*clause
+	polarity []bool
+	activity []float64
+	seen     []bool
+	order    variableOrder
+
+	trail    []literal
+	trailLim []int
+	qhead    int
+
+	assumptions []literal
+	conflict    []literal
+
+	model operators.Model
+	core  []operators.Term
+	proof *proofWriter
+
+	ok           bool
+	varInc       float64
+	clauseInc    float64
+	maxLearnts   float64
+	learntAdjust float64
+	adjustCount  int
+
+	stats    Stats
+	progress progress
+
+	rng             *rand.Rand
+	randomFrequency float64
+
+	exchange     *clauseExchange
+	exchangeRead int
+
+	conflictBudget    int
+	propagationBudget int
+	conflictLimit     int
+	propagationLimit  int
+	done              <-chan struct{}
+}
+
+// NewSolver creates an empty incremental solver
+func NewSolver() *Solver {
+	s := &Solver{
+		index:     make(map[interface{}]int),
+		ok:        true,
+		varInc:    1,
+		clauseInc: 1,
+	}
+	s.order.s = s
+	return s
+}
+
+// newVariable registers v in the solver and returns its index.
+// Auxiliary variables, that cannot be referred to by a term, are registered with v == nil.
+func (s *Solver) newVariable(v operators.Variable) int {
+	i := len(s.variables)
+	s.variables = append(s.variables, v)
+	if v != nil {
+		s.index[operators.VariableKey(v)] = i
+	}
+	s.proof.register(v)
+	s.watches = append(s.watches, nil, nil)
+	s.assigns = append(s.assigns, lUndef)
+	s.level = append(s.level, 0)
+	s.reason = append(s.reason, nil)
+	s.polarity = append(s.polarity, true)
+	if s.rng != nil {
+		// break the ties between new variables randomly, below the first bump
+		s.activity = append(s.activity, s.rng.Float64()*1e-5)
+	} else {
+		s.activity = append(s.activity, 0)
+	}
+	s.seen = append(s.seen, false)
+	s.order.insert(i)
+	return i
+}
+
+// literal converts a term to its internal representation.
+// If the term is a constant, the constant is returned instead.
+func (s *Solver) literal(t operators.Term) (literal, operators.Constant) {
+	negative := false
+	for {
+		if c, ok := t.(operators.Constant); ok {
+			if negative {
+				return undefLiteral, c.Negate().(operators.Constant)
+			}
+			return undefLiteral, c
+		} else if neg, ok := t.(*operators.Negation); ok {
+			negative = !negative
+			t = neg.Negate()
+		} else {
+			break
+		}
+	}
+
+	v := t.Variable()
+	i, ok := s.index[operators.VariableKey(v)]
+	if !ok {
+		i = s.newVariable(v)
+	}
+
+	return makeLiteral(i, negative), nil
+}
+
+// modelValue returns the value of v in the model found by the last call to Solve
+func (s *Solver) modelValue(v operators.Variable) bool {
+	l, _ := s.literal(v)
+	return s.model[s.variables[l.variable()]]
+}
+
+// term converts an internal literal back to a term
+func (s *Solver) term(l literal) operators.Term {
+	v := s.variables[l.variable()]
+	if l.negative() {
+		return v.Negate()
+	}
+	return v
+}
+
+func (s *Solver) value(l literal) lbool {
+	v := s.assigns[l.variable()]
+	if v == lUndef || !l.negative() {
+		return v
+	}
+	if v == lTrue {
+		return lFalse
+	}
+	return lTrue
+}
+
+func (s *Solver) decisionLevel() int {
+	return len(s.trailLim)
+}
+
+// AddClause adds a clause to the solver.
+// It returns false iff the clauses in the solver are known to be unsatisfiable.
+func (s *Solver) AddClause(cnfClause operators.CNFClause) bool {
+	if !s.ok {
+		return false
+	}
+
+	// register every variable before simplifying, such that variables are numbered in order of occurrence
+	satisfied := false
+	lits := make([]literal, 0, cnfClause.NumTerms())
+	for _, t := range cnfClause.Terms() {
+		lit, c := s.literal(t)
+		if c != nil {
+			// the clause is satisfied by a true constant, false constants are ignored
+			satisfied = satisfied || c.Value()
+			continue
+		}
+		lits = append(lits, lit)
+	}
+
+	if satisfied {
+		return true
+	}
+
+	sort.Slice(lits, func(i, j int) bool {
+		return lits[i] < lits[j]
+	})
+
+	// remove duplicates and falsified literals, skip tautologies and satisfied clauses
+	j, prev, shortened := 0, undefLiteral, false
+	for _, l := range lits {
+		if s.value(l) == lTrue || (prev != undefLiteral && l == prev.negate()) {
+			return true
+		}
+		if s.value(l) == lFalse {
+			shortened = true
+		} else if l != prev {
+			lits[j] = l
+			prev = l
+			j++
+		}
+	}
+	lits = lits[:j]
+
+	if shortened {
+		// the shortened clause follows from the unit clauses found so far
+		s.proof.add(lits)
+	}
+
+	switch len(lits) {
+	case 0:
+		s.ok = false
+	case 1:
+		s.enqueue(lits[0], nil)
+		if s.propagate() != nil {
+			s.proof.add(nil)
+			s.ok = false
+		}
+	default:
+		c := &clause{lits: lits}
+		s.clauses = append(s.clauses, c)
+		s.attach(c)
+	}
+
+	return s.ok
+}
+
+// AddCNF adds every clause in cnf to the solver.
+// It returns false iff the clauses in the solver are known to be unsatisfiable.
+func (s *Solver) AddCNF(cnf operators.CNF) bool {
+	for _, c := range cnf {
+		if !s.AddClause(c) {
+			return false
+		}
+	}
+	return s.ok
+}
+
+// Solve searches for a satisfying assignment of the clauses under the given assumptions.
+// After a satisfiable result, Model returns the assignment.
+// After an unsatisfiable result, Core returns the subset of assumptions responsible.
+// Solve returns false if the search is stopped by the budget of the solver, use SolveContext to distinguish between
+// an unsatisfiable and an unknown result.
+func (s *Solver) Solve(assumptions ...operators.Term) bool {
+	return s.SolveContext(context.Background(), assumptions...) == Satisfiable
+}
+
+// SolveContext is like Solve, but stops the search when ctx is done or the budget of the solver is exhausted.
+// In that case, the status is Unknown.
+func (s *Solver) SolveContext(ctx context.Context, assumptions ...operators.Term) Status {
+	s.model = nil
+	s.core = nil
+	// the conflict of a previous call would otherwise be reported as the core of an Unknown result
+	s.conflict = s.conflict[:0]
+
+	defer s.proof.flush()
+
+	if !s.ok {
+		return Unsatisfiable
+	}
+
+	assumed := make(map[literal]operators.Term, len(assumptions))
+	s.assumptions = s.assumptions[:0]
+	for _, t := range assumptions {
+		lit, c := s.literal(t)
+		if c != nil {
+			if !c.Value() {
+				s.core = []operators.Term{t}
+				return Unsatisfiable
+			}
+			continue
+		}
+		if _, ok := assumed[lit]; !ok {
+			assumed[lit] = t
+		}
+		s.assumptions = append(s.assumptions, lit)
+	}
+
+	if s.maxLearnts == 0 {
+		s.maxLearnts = math.Max(float64(len(s.clauses))/3, 100)
+		s.learntAdjust = 100
+		s.adjustCount = 100
+	}
+
+	s.done = ctx.Done()
+	s.conflictLimit, s.propagationLimit = -1, -1
+	if s.conflictBudget > 0 {
+		s.conflictLimit = s.stats.Conflicts + s.conflictBudget
+	}
+	if s.propagationBudget > 0 {
+		s.propagationLimit = s.stats.Propagations + s.propagationBudget
+	}
+
+	status := lUndef
+	for restarts := 0; status == lUndef && s.withinBudget(); restarts++ {
+		// clauses that are learnt by other solvers are added in between restarts, at decision level 0
+		if !s.importClauses() {
+			status = lFalse
+			break
+		}
+		status = s.search(int(luby(2, restarts) * 100))
+	}
+
+	if status == lTrue {
+		s.model = make(operators.Model, len(s.variables))
+		for i, v := range s.variables {
+			if v != nil {
+				s.model[v] = s.assigns[i] == lTrue
+			}
+		}
+	} else if status == lFalse && s.ok {
+		s.core = make([]operators.Term, 0, len(s.conflict))
+		for _, l := range s.conflict {
+			s.core = append(s.core, assumed[l.negate()])
+		}
+	}
+
+	s.cancelUntil(0)
+
+	switch status {
+	case lTrue:
+		return Satisfiable
+	case lFalse:
+		return Unsatisfiable
+	default:
+		return Unknown
+	}
+}
+
+// SetBudget limits the number of conflicts and propagations of every subsequent call to Solve.
+// A budget of zero or less is unlimited.
+func (s *Solver) SetBudget(conflicts, propagations int) {
+	s.conflictBudget = conflicts
+	s.propagationBudget = propagations
+}
+
+// SetRandom diversifies the search of the solver, e.g. to run differently configured solvers in a Portfolio.
+// The initial activities of the variables are random, such that ties are broken differently for every seed, and with
+// probability frequency a random variable is decided instead of the most active one.
+// SetRandom should be called before any clause is added.
+func (s *Solver) SetRandom(seed int64, frequency float64) {
+	s.rng = rand.New(rand.NewSource(seed))
+	s.randomFrequency = frequency
+}
+
+// withinBudget returns false if the search should be stopped
+func (s *Solver) withinBudget() bool {
+	if s.conflictLimit >= 0 && s.stats.Conflicts >= s.conflictLimit {
+		return false
+	}
+	if s.propagationLimit >= 0 && s.stats.Propagations >= s.propagationLimit {
+		return false
+	}
+
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// SetProof streams a DRAT proof of every learnt and deleted clause to w.
+// The proof refers to variables by their number in the given numbering, such that it can be verified against the
+// DIMACS file written with the same numbering. If numbering is nil, variables are numbered in order of first
+// occurrence, but positive integer variables keep their own number.
+// SetProof should be called before any clause is added.
+func (s *Solver) SetProof(w io.Writer, format ProofFormat, numbering *operators.Numbering) {
+	if numbering == nil {
+		numbering = operators.NewNumbering(nil)
+	}
+
+	s.proof = newProofWriter(w, format, numbering)
+	for _, v := range s.variables {
+		s.proof.register(v)
+	}
+}
+
+// ProofErr returns the first error that occurred while writing the proof
+func (s *Solver) ProofErr() error {
+	return s.proof.error()
+}
+
+// Model returns the satisfying assignment found by the last call to Solve
+func (s *Solver) Model() operators.Model {
+	return s.model
+}
+
+// Stats returns the statistics of every call to Solve so far
+func (s *Solver) Stats() Stats {
+	return s.stats
+}
+
+// SetProgress calls fn with the statistics of the solver after every interval conflicts.
+// A nil fn disables progress reporting.
+func (s *Solver) SetProgress(interval int, fn ProgressFunc) {
+	s.progress.set(interval, fn)
+}
+
+// Core returns the subset of assumptions that made the last call to Solve unsatisfiable.
+// The core is empty if the clauses are unsatisfiable without any assumptions.
+func (s *Solver) Core() []operators.Term {
+	return s.core
+}
+
+func (s *Solver) search(nofConflicts int) lbool {
+	conflictC := 0
+
+	for {
+		confl := s.propagate()
+		if confl != nil {
+			s.stats.Conflicts++
+			conflictC++
+			s.progress.step(s.stats)
+
+			// a conflict found by Gauss-Jordan elimination can be falsified at a lower decision level
+			if level := s.conflictLevel(confl); level < s.decisionLevel() {
+				s.cancelUntil(level)
+			}
+
+			if s.decisionLevel() == 0 {
+				s.proof.add(nil)
+				s.ok = false
+				return lFalse
+			}
+
+			learnt, btLevel := s.analyze(confl)
+			s.cancelUntil(btLevel)
+			s.proof.add(learnt)
+			s.stats.Learnts++
+			s.export(learnt)
+
+			if len(learnt) == 1 {
+				s.enqueue(learnt[0], nil)
+			} else {
+				c := &clause{lits: learnt, learnt: true}
+				s.learnts = append(s.learnts, c)
+				s.attach(c)
+				s.bumpClause(c)
+				s.enqueue(learnt[0], c)
+			}
+
+			s.varInc /= 0.95
+			s.clauseInc /= 0.999
+
+			// allow more learnt clauses as the search progresses
+			if s.adjustCount--; s.adjustCount == 0 {
+				s.learntAdjust *= 1.5
+				s.adjustCount = int(s.learntAdjust)
+				s.maxLearnts *= 1.1
+			}
+
+			if !s.withinBudget() {
+				s.cancelUntil(0)
+				return lUndef
+			}
+		} else {
+			if nofConflicts >= 0 && conflictC >= nofConflicts {
+				// restart
+				s.cancelUntil(0)
+				s.stats.Restarts++
+				return lUndef
+			}
+
+			if !s.withinBudget() {
+				s.cancelUntil(0)
+				return lUndef
+			}
+
+			if float64(len(s.learnts)-len(s.trail)) >= s.maxLearnts {
+				s.reduceDB()
+			}
+
+			next := undefLiteral
+			for s.decisionLevel() < len(s.assumptions) {
+				p := s.assumptions[s.decisionLevel()]
+				if s.value(p) == lTrue {
+					// dummy decision level
+					s.newDecisionLevel()
+				} else if s.value(p) == lFalse {
+					s.analyzeFinal(p.negate())
+					return lFalse
+				} else {
+					next = p
+					break
+				}
+			}
+
+			if next == undefLiteral {
+				s.stats.Decisions++
+				if next = s.pickBranchLiteral(); next == undefLiteral {
+					// every variable is assigned: model found
+					return lTrue
+				}
+			}
+
+			s.newDecisionLevel()
+			s.enqueue(next, nil)
+		}
+	}
+}
+
+func (s *Solver) newDecisionLevel() {
+	s.trailLim = append(s.trailLim, len(s.trail))
+}
+
+func (s *Solver) enqueue(l literal, from *clause) {
+	v := l.variable()
+	s.assigns[v] = liftBool(!l.negative())
+	s.level[v] = s.decisionLevel()
+	s.reason[v] = from
+	s.trail = append(s.trail, l)
+
+	if c := s.gauss.columnOf(v); c >= 0 {
+		s.gauss.set(c, s.assigns[v])
+	}
+}
+
+func (s *Solver) cancelUntil(level int) {
+	if s.decisionLevel() <= level {
+		return
+	}
+
+	for c := len(s.trail) - 1; c >= s.trailLim[level]; c-- {
+		v := s.trail[c].variable()
+		s.assigns[v] = lUndef
+		s.reason[v] = nil
+		s.polarity[v] = s.trail[c].negative()
+		s.order.insert(v)
+
+		if column := s.gauss.columnOf(v); column >= 0 {
+			s.gauss.set(column, lUndef)
+		}
+	}
+
+	s.qhead = s.trailLim[level]
+	s.trail = s.trail[:s.trailLim[level]]
+	s.trailLim = s.trailLim[:level]
+
+	if len(s.gauss.rows) > 0 {
+		s.gauss.repair()
+	}
+}
+
+func (s *Solver) pickBranchLiteral() literal {
+	if s.rng != nil && !s.order.empty() && s.rng.Float64() < s.randomFrequency {
+		if v := s.order.heap[s.rng.Intn(len(s.order.heap))]; s.assigns[v] == lUndef {
+			return makeLiteral(v, s.polarity[v])
+		}
+	}
+
+	for !s.order.empty() {
+		v := s.order.removeMin()
+		if s.assigns[v] == lUndef {
+			return makeLiteral(v, s.polarity[v])
+		}
+	}
+	return undefLiteral
+}
+
+func (s *Solver) attach(c *clause) {
+	s.watches[c.lits[0].negate()] = append(s.watches[c.lits[0].negate()], watcher{c, c.lits[1]})
+	s.watches[c.lits[1].negate()] = append(s.watches[c.lits[1].negate()], watcher{c, c.lits[0]})
+}
+
+func (s *Solver) detach(c *clause) {
+	for _, l := range c.lits[:2] {
+		ws := s.watches[l.negate()]
+		for i := range ws {
+			if ws[i].clause == c {
+				s.watches[l.negate()] = append(ws[:i], ws[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// locked returns true iff c is the reason of a current assignment
+func (s *Solver) locked(c *clause) bool {
+	return s.reason[c.lits[0].variable()] == c && s.value(c.lits[0]) == lTrue
+}
+
+// propagate applies unit propagation on all enqueued literals and returns the conflicting clause, if any
+func (s *Solver) propagate() *clause {
+	var confl *clause
+
+	// rows of the xor matrix that changed before backtracking can imply a literal under the current assignment
+	if len(s.gauss.pending) > 0 {
+		if confl = s.checkPending(); confl != nil {
+			return confl
+		}
+	}
+
+	for s.qhead < len(s.trail) {
+		p := s.trail[s.qhead]
+		s.qhead++
+		s.stats.Propagations++
+
+		falseLit := p.negate()
+		ws := s.watches[p]
+		i, j := 0, 0
+
+		for i < len(ws) {
+			w := ws[i]
+			i++
+
+			// the blocker is true: the clause is satisfied
+			if s.value(w.blocker) == lTrue {
+				ws[j] = w
+				j++
+				continue
+			}
+
+			// make sure the false literal is at position 1
+			c := w.clause
+			if c.lits[0] == falseLit {
+				c.lits[0], c.lits[1] = c.lits[1], falseLit
+			}
+
+			first := c.lits[0]
+			nw := watcher{c, first}
+			if first != w.blocker && s.value(first) == lTrue {
+				ws[j] = nw
+				j++
+				continue
+			}
+
+			// look for a new literal to watch
+			found := false
+			for k := 2; k < len(c.lits); k++ {
+				if s.value(c.lits[k]) != lFalse {
+					c.lits[1], c.lits[k] = c.lits[k], falseLit
+					s.watches[c.lits[1].negate()] = append(s.watches[c.lits[1].negate()], nw)
+					found = true
+					break
+				}
+			}
+			if found {
+				continue
+			}
+
+			// the clause is unit or conflicting
+			ws[j] = nw
+			j++
+			if s.value(first) == lFalse {
+				confl = c
+				s.qhead = len(s.trail)
+				for i < len(ws) {
+					ws[j] = ws[i]
+					i++
+					j++
+				}
+			} else {
+				s.enqueue(first, c)
+			}
+		}
+
+		s.watches[p] = ws[:j]
+
+		if confl == nil && len(s.gauss.rows) > 0 {
+			if confl = s.propagateXor(p.variable()); confl != nil {
+				s.qhead = len(s.trail)
+			}
+		}
+	}
+
+	return confl
+}
+
+// analyze derives a learnt clause from a conflict (first unique implication point) and returns the backtrack level
+func (s *Solver) analyze(confl *clause) ([]literal, int) {
+	learnt := []literal{undefLiteral}
+	pathC := 0
+	p := undefLiteral
+	index := len(s.trail) - 1
+
+	for {
+		if confl.learnt {
+			s.bumpClause(confl)
+		}
+
+		start := 0
+		if p != undefLiteral {
+			start = 1
+		}
+
+		for _, q := range confl.lits[start:] {
+			v := q.variable()
+			if !s.seen[v] && s.level[v] > 0 {
+				s.bumpVariable(v)
+				s.seen[v] = true
+				if s.level[v] >= s.decisionLevel() {
+					pathC++
+				} else {
+					learnt = append(learnt, q)
+				}
+			}
+		}
+
+		// select the next literal to look at
+		for !s.seen[s.trail[index].variable()] {
+			index--
+		}
+		p = s.trail[index]
+		index--
+		confl = s.reason[p.variable()]
+		s.seen[p.variable()] = false
+		pathC--
+
+		if pathC <= 0 {
+			break
+		}
+	}
+	learnt[0] = p.negate()
+
+	// remove literals that are implied by other literals in the learnt clause
+	toClear := append([]literal(nil), learnt...)
+	j := 1
+	for i := 1; i < len(learnt); i++ {
+		if r := s.reason[learnt[i].variable()]; r == nil || !s.redundant(r) {
+			learnt[j] = learnt[i]
+			j++
+		}
+	}
+	learnt = learnt[:j]
+
+	// find the backtrack level and move the corresponding literal to position 1
+	btLevel := 0
+	if len(learnt) > 1 {
+		max := 1
+		for i := 2; i < len(learnt); i++ {
+			if s.level[learnt[i].variable()] > s.level[learnt[max].variable()] {
+				max = i
+			}
+		}
+		learnt[1], learnt[max] = learnt[max], learnt[1]
+		btLevel = s.level[learnt[1].variable()]
+	}
+
+	for _, l := range toClear {
+		s.seen[l.variable()] = false
+	}
+
+	return learnt, btLevel
+}
+
+// conflictLevel returns the highest decision level of the literals in a conflicting clause
+func (s *Solver) conflictLevel(confl *clause) int {
+	level := 0
+	for _, l := range confl.lits {
+		if s.level[l.variable()] > level {
+			level = s.level[l.variable()]
+		}
+	}
+	return level
+}
+
+func (s *Solver) redundant(reason *clause) bool {
+	for _, q := range reason.lits[1:] {
+		if v := q.variable(); !s.seen[v] && s.level[v] > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// analyzeFinal computes the set of assumptions that led to the assignment of p.
+// The result is stored as a clause of negated assumptions in s.conflict.
+func (s *Solver) analyzeFinal(p literal) {
+	s.conflict = append(s.conflict[:0], p)
+
+	if s.decisionLevel() == 0 {
+		return
+	}
+
+	s.seen[p.variable()] = true
+
+	for i := len(s.trail) - 1; i >= s.trailLim[0]; i-- {
+		v := s.trail[i].variable()
+		if !s.seen[v] {
+			continue
+		}
+
+		if r := s.reason[v]; r == nil {
+			s.conflict = append(s.conflict, s.trail[i].negate())
+		} else {
+			for _, q := range r.lits[1:] {
+				if s.level[q.variable()] > 0 {
+					s.seen[q.variable()] = true
+				}
+			}
+		}
+		s.seen[v] = false
+	}
+
+	s.seen[p.variable()] = false
+}
+
+func (s *Solver) bumpVariable(v int) {
+	if s.activity[v] += s.varInc; s.activity[v] > 1e100 {
+		// rescale all activities
+		for i := range s.activity {
+			s.activity[i] *= 1e-100
+		}
+		s.varInc *= 1e-100
+	}
+	s.order.update(v)
+}
+
+func (s *Solver) bumpClause(c *clause) {
+	if c.activity += s.clauseInc; c.activity > 1e20 {
+		for _, l := range s.learnts {
+			l.activity *= 1e-20
+		}
+		s.clauseInc *= 1e-20
+	}
+}
+
+// reduceDB removes half of the learnt clauses, except for clauses that are currently used as a reason
+func (s *Solver) reduceDB() {
+	sort.Slice(s.learnts, func(i, j int) bool {
+		return s.learnts[i].activity < s.learnts[j].activity
+	})
+
+	j := 0
+	for i, c := range s.learnts {
+		if i < len(s.learnts)/2 && len(c.lits) > 2 && !s.locked(c) {
+			s.detach(c)
+			s.proof.delete(c.lits)
+		} else {
+			s.learnts[j] = c
+			j++
+		}
+	}
+	s.learnts = s.learnts[:j]
+}
+
+// luby computes the x'th element of the Luby sequence with base y
+func luby(y float64, x int) float64 {
+	size, seq := 1, 0
+	for size < x+1 {
+		seq++
+		size = 2*size + 1
+	}
+
+	for size-1 != x {
+		size = (size - 1) >> 1
+		seq--
+		x = x % size
+	}
+
+	return math.Pow(y, float64(seq))
+}
+
+// variableOrder is a binary heap of variables, ordered by decreasing activity
+type variableOrder struct {
+	s       *Solver
+	heap    []int
+	indices []int
+}
+
+func (o *variableOrder) less(a, b int) bool {
+	return o.s.activity[a] > o.s.activity[b]
+}
+
+func (o *variableOrder) empty() bool {
+	return len(o.heap) == 0
+}
+
+func (o *variableOrder) contains(v int) bool {
+	return v < len(o.indices) && o.indices[v] >= 0
+}
+
+func (o *variableOrder) insert(v int) {
+	for len(o.indices) <= v {
+		o.indices = append(o.indices, -1)
+	}
+	if o.contains(v) {
+		return
+	}
+	o.indices[v] = len(o.heap)
+	o.heap = append(o.heap, v)
+	o.up(o.indices[v])
+}
+
+func (o *variableOrder) update(v int) {
+	if o.contains(v) {
+		o.up(o.indices[v])
+	}
+}
+
+func (o *variableOrder) removeMin() int {
+	v := o.heap[0]
+	last := o.heap[len(o.heap)-1]
+	o.heap[0] = last
+	o.indices[last] = 0
+	o.indices[v] = -1
+	o.heap = o.heap[:len(o.heap)-1]
+	if len(o.heap) > 1 {
+		o.down(0)
+	}
+	return v
+}
+
+func (o *variableOrder) up(i int) {
+	v := o.heap[i]
+	for i > 0 {
+		parent := (i - 1) >> 1
+		if !o.less(v, o.heap[parent]) {
+			break
+		}
+		o.heap[i] = o.heap[parent]
+		o.indices[o.heap[i]] = i
+		i = parent
+	}
+	o.heap[i] = v
+	o.indices[v] = i
+}
+
+func (o *variableOrder) down(i int) {
+	v := o.heap[i]
+	for 2*i+1 < len(o.heap) {
+		child := 2*i + 1
+		if child+1 < len(o.heap) && o.less(o.heap[child+1], o.heap[child]) {
+			child++
+		}
+		if !o.less(o.heap[child], v) {
+			break
+		}
+		o.heap[i] = o.heap[child]
+		o.indices[o.heap[i]] = i
+		i = child
+	}
+	o.heap[i] = v
+	o.indices[v] = i
+}
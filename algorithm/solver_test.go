@@ -0,0 +1,115 @@
+package algorithm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+func TestSolverSat(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c := operators.Var("a"), operators.Var("b"), operators.Var("c")
+
+	s := NewSolver()
+	s.AddCNF(operators.CNF{
+		operators.NClause{a, b.Negate()},
+		operators.NClause{c, a},
+		operators.NClause{a.Negate(), c.Negate()},
+	})
+
+	be.Assert("clauses are sat", s.Solve())
+
+	model := s.Model()
+	be.AssertInfo("model satisfies clauses", (model[a] || !model[b]) && (model[c] || model[a]) && !(model[a] && model[c]), model)
+}
+
+func TestSolverIncremental(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b := operators.Var("a"), operators.Var("b")
+
+	s := NewSolver()
+	s.AddClause(operators.NClause{a, b})
+
+	be.Assert("a or b is sat", s.Solve())
+
+	s.AddClause(a.Negate())
+	be.Assert("a or b and not a is sat", s.Solve())
+	be.Assert("b must be true", s.Model()[b])
+
+	s.AddClause(b.Negate())
+	be.Assert("a or b and not a and not b is unsat", !s.Solve())
+	be.Assert("core is empty without assumptions", len(s.Core()) == 0)
+}
+
+func TestSolverAssumptionCore(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c, d := operators.Var("a"), operators.Var("b"), operators.Var("c"), operators.Var("d")
+
+	s := NewSolver()
+	s.AddCNF(operators.CNF{
+		operators.NClause{a.Negate(), b},
+		operators.NClause{b.Negate(), c},
+	})
+
+	notC := c.Negate()
+
+	be.Assert("a, d and not c are inconsistent", !s.Solve(d, a, notC))
+
+	core := s.Core()
+	be.AssertInfo("core contains a and not c", len(core) == 2 && containsTerm(core, a) && containsTerm(core, notC), core)
+
+	// the solver remains usable after an unsatisfiable result under assumptions
+	be.Assert("a and d are consistent", s.Solve(a, d))
+	be.Assert("c follows from a", s.Model()[c])
+}
+
+func TestSolverUnknownCore(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b := operators.Var("a"), operators.Var("b")
+
+	s := NewSolver()
+	s.AddClause(operators.NClause{a.Negate(), b.Negate()})
+
+	be.Assert("a and b are inconsistent", !s.Solve(a, b))
+	be.AssertInfo("core contains a and b", len(s.Core()) == 2, s.Core())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	status := s.SolveContext(ctx, b)
+	be.AssertInfo("cancelled context results in unknown", status == Unknown, status)
+	be.AssertInfo("an unknown result has no core", len(s.Core()) == 0, s.Core())
+}
+
+func TestUnsatCore(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c := operators.Var("a"), operators.Var("b"), operators.Var("c")
+
+	cnf := operators.CNF{
+		operators.NClause{c, b},
+		operators.NClause{a},
+		operators.NClause{a.Negate(), b},
+		operators.NClause{c.Negate(), a},
+		operators.NClause{b.Negate()},
+	}
+
+	core, sat := UnsatCore(cnf)
+	be.Assert("cnf is unsat", !sat)
+	be.AssertInfo("core is a subset of the clauses", len(core) <= 3, core)
+
+	be.Assert("core is unsat", !CDCL(core).Sat())
+
+	_, sat = UnsatCore(cnf[:4])
+	be.Assert("cnf without not b is sat", sat)
+}
+
+func containsTerm(terms []operators.Term, term operators.Term) bool {
+	for _, t := range terms {
+		if t.TermEquivalent(term) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package algorithm
+
+// Stats counts the work done by a solver.
+// The CDCL solvers count decisions, propagations, conflicts, learnt clauses and restarts,
+// the BDD construction counts the created nodes and the hits in its apply cache, and local search counts its flips.
+type Stats struct {
+	Decisions    int
+	Propagations int
+	Conflicts    int
+	Learnts      int
+	Restarts     int
+	Nodes        int
+	CacheHits    int
+	Flips        int
+}
+
+// ProgressFunc receives the statistics of a running solver
+type ProgressFunc func(Stats)
+
+// progress calls a ProgressFunc every interval steps
+type progress struct {
+	fn       ProgressFunc
+	interval int
+	count    int
+}
+
+// step counts a step, and reports the statistics if the interval has passed
+func (p *progress) step(stats Stats) {
+	if p.fn == nil {
+		return
+	}
+
+	if p.count++; p.count >= p.interval {
+		p.count = 0
+		p.fn(stats)
+	}
+}
+
+func (p *progress) set(interval int, fn ProgressFunc) {
+	if interval < 1 {
+		interval = 1
+	}
+
+	p.fn = fn
+	p.interval = interval
+	p.count = 0
+}
+
+// add returns the sum of the statistics of two solvers
+func (s Stats) add(other Stats) Stats {
+	return Stats{
+		Decisions:    s.Decisions + other.Decisions,
+		Propagations: s.Propagations + other.Propagations,
+		Conflicts:    s.Conflicts + other.Conflicts,
+		Learnts:      s.Learnts + other.Learnts,
+		Restarts:     s.Restarts + other.Restarts,
+		Nodes:        s.Nodes + other.Nodes,
+		CacheHits:    s.CacheHits + other.CacheHits,
+		Flips:        s.Flips + other.Flips,
+	}
+}
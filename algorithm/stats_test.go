@@ -0,0 +1,69 @@
+package algorithm
+
+import (
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+	"github.com/timbeurskens/gobdd/operators/bdd"
+)
+
+func TestSolverStats(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	var reports []Stats
+
+	s := NewSolver()
+	s.SetProgress(10, func(stats Stats) {
+		reports = append(reports, stats)
+	})
+	s.AddCNF(pigeonhole(5))
+
+	be.Assert("pigeonhole is unsat", !s.Solve())
+
+	stats := s.Stats()
+	be.AssertInfo("solver counts its work", stats.Decisions > 0 && stats.Propagations > 0 && stats.Conflicts > 0 && stats.Learnts > 0, stats)
+	be.AssertInfo("progress is reported every 10 conflicts", len(reports) == stats.Conflicts/10, len(reports), stats.Conflicts)
+	be.AssertInfo("progress reports the conflicts so far", len(reports) > 0 && reports[0].Conflicts == 10, reports)
+}
+
+func TestCDCLStats(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b := operators.Var("a"), operators.Var("b")
+
+	cnf := operators.CNF{
+		operators.NClause{a, b},
+		operators.NClause{a, b.Negate()},
+		operators.NClause{a.Negate(), b},
+		operators.NClause{a.Negate(), b.Negate()},
+	}
+
+	result := CDCL(cnf)
+	be.Assert("cnf is unsat", !result.Sat())
+	be.AssertInfo("cdcl counts decisions, conflicts and learnt clauses", result.Stats.Decisions > 0 && result.Stats.Conflicts > 0 && result.Stats.Learnts > 0, result.Stats)
+
+	result = RecursiveCDCL(cnf)
+	be.Assert("cnf is unsat", !result.Sat())
+	be.AssertInfo("recursive cdcl counts decisions and conflicts", result.Stats.Decisions > 0 && result.Stats.Conflicts > 0, result.Stats)
+}
+
+func TestBuilderStats(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c, d := operators.Var("a"), operators.Var("b"), operators.Var("c"), operators.Var("d")
+
+	nodes := 0
+
+	builder := NewBuilder()
+	builder.SetProgress(1, func(stats Stats) {
+		nodes = stats.Nodes
+	})
+
+	expr := operators.Xor(operators.Xor(a, b), operators.Xor(c, d))
+	tree := builder.FromExpression(expr)
+
+	be.Assert("bdd is equivalent to a bdd without cache", bdd.Equivalent(tree, FromExpression(expr)))
+
+	stats := builder.Stats()
+	be.AssertInfo("builder counts created nodes", stats.Nodes > 0 && nodes == stats.Nodes, stats)
+	be.AssertInfo("shared sub-diagrams hit the apply cache", stats.CacheHits > 0, stats)
+}
@@ -1,60 +1,296 @@
 package algorithm
 
-import "github.com/timbeurskens/gobdd/operators"
+import (
+	"fmt"
+	"reflect"
 
-// TransformTseitin uses the Tseitin transformation to convert an arbitrary expression into CNF
-// assume e is in NNF (negation normal form)
-func TransformTseitin(e operators.Expression) operators.CNF {
-	nMax := operators.Size(e)
-	result := make(operators.CNF, 1, nMax)
-	queue := make([][2]operators.Expression, 1, nMax)
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// TseitinOptions selects variants of the Tseitin transformation
+type TseitinOptions struct {
+	// NativeXor keeps exclusive disjunctions and bi-implications as xor clauses, to be solved by CDCLXor, instead of
+	// encoding them in CNF. Nested exclusive disjunctions, bi-implications and negations are flattened into a single
+	// xor clause.
+	NativeXor bool
 
-	start := operators.IncVar()
+	// Polarity selects the Plaisted-Greenbaum encoding: every subexpression only gets the direction of its
+	// bi-implication that is needed for the polarity in which it occurs, which removes roughly half of the clauses.
+	// The result is still satisfiable iff e is satisfiable, and every model of the result is a model of e, but the
+	// auxiliary variables are no longer determined by the variables of e. Models should therefore be projected onto
+	// the variables of e when counting or enumerating them.
+	Polarity bool
+}
 
-	var work [2]operators.Expression
+// polarity is the set of directions of the bi-implication v ⟷ e of a subexpression e that have to be encoded
+type polarity uint8
 
-	var leftVar, rightVar operators.Variable
-	var exprSplit operators.Expression
+const (
+	// positive encodes v → e, for subexpressions that only occur positively
+	positive polarity = 1 << iota
+	// negative encodes e → v, for subexpressions that only occur negatively
+	negative
+	both = positive | negative
+)
+
+// flip is the polarity of the operand of a negation
+func (p polarity) flip() polarity {
+	return p&positive<<1 | p&negative>>1
+}
+
+// tseitinItem is a subexpression e, to be encoded by the variable v in the given polarity
+type tseitinItem struct {
+	v        operators.Variable
+	e        operators.Expression
+	polarity polarity
+}
+
+// nodeKey identifies a node by its type and the identifiers of its children, such that structurally identical
+// subexpressions have the same key. Leafs are identified by their name.
+type nodeKey struct {
+	kind                reflect.Type
+	left, right, choice int
+	name                string
+}
+
+// sharedVar is the auxiliary variable of a subexpression, and the polarity in which it has been encoded so far
+type sharedVar struct {
+	v        operators.Variable
+	polarity polarity
+}
+
+// tseitin is the state of a Tseitin transformation: structurally identical subexpressions share one auxiliary
+// variable, and variables and negations of subexpressions are used directly as operands
+type tseitin struct {
+	opts  TseitinOptions
+	queue []tseitinItem
+	xors  []XorClause
+
+	ids  map[operators.Node]int
+	keys map[nodeKey]int
+	vars map[int]*sharedVar
+	next int
+}
 
-	queue[0] = [2]operators.Expression{start, e}
-	result[0] = start
+// TransformTseitin uses the Tseitin transformation to convert an arbitrary expression into CNF.
+// Every operator is encoded directly by its own clause pattern, including implications, bi-implications, exclusive
+// disjunctions, negations of subexpressions and choices (if-then-else on a variable), so e does not have to be
+// normalized first. Normalization would copy the operands of every exclusive disjunction and bi-implication.
+// Structurally identical subexpressions are encoded once, by a single auxiliary variable.
+func TransformTseitin(e operators.Expression) operators.CNF {
+	cnf, _ := TransformTseitinWith(e, TseitinOptions{})
+	return cnf
+}
+
+// TransformTseitinWith is the Tseitin transformation with options.
+// The xor clauses are empty, unless opts.NativeXor is set.
+func TransformTseitinWith(e operators.Expression, opts TseitinOptions) (operators.CNF, []XorClause) {
+	t := &tseitin{
+		opts: opts,
+		ids:  make(map[operators.Node]int),
+		keys: make(map[nodeKey]int),
+		vars: make(map[int]*sharedVar),
+	}
 
-	for len(queue) > 0 {
-		work, queue = queue[0], queue[1:]
+	// without the polarity option, every subexpression is encoded in both directions
+	rootPolarity := both
+	if opts.Polarity {
+		rootPolarity = positive
+	}
+
+	result := operators.CNF{t.operand(e, rootPolarity)}
+
+	var work tseitinItem
+	var exprSplit operators.Expression
 
-		leftVar = operators.IncVar().(operators.Variable)
-		rightVar = operators.IncVar().(operators.Variable)
+	for len(t.queue) > 0 {
+		work, t.queue = t.queue[0], t.queue[1:]
 
-		switch work[1].(type) {
+		switch work.e.(type) {
 		case operators.Constant:
-			exprSplit = work[1]
-		case operators.Variable:
-			exprSplit = work[1]
-		case *operators.Negation:
-			// negations can only occur on a variable
-			exprSplit = work[1]
+			exprSplit = work.e
+		case *operators.Cardinality:
+			// cardinality constraints are encoded as a whole
+			result = append(result, polarityClauses(work.v, work.polarity, reifyCardinality(work.v, work.e.(*operators.Cardinality)))...)
+			continue
+		case *operators.PseudoBoolean:
+			result = append(result, polarityClauses(work.v, work.polarity, reifyPseudoBoolean(work.v, work.e.(*operators.PseudoBoolean)))...)
+			continue
 		case *operators.Conjunction:
 			exprSplit = &operators.Conjunction{
-				A: leftVar,
-				B: rightVar,
+				A: t.operand(work.e.LeftChild(), work.polarity),
+				B: t.operand(work.e.RightChild(), work.polarity),
 			}
-			queue = append(queue, [2]operators.Expression{leftVar, work[1].LeftChild()})
-			queue = append(queue, [2]operators.Expression{rightVar, work[1].RightChild()})
 		case *operators.Disjunction:
 			exprSplit = &operators.Disjunction{
-				A: leftVar,
-				B: rightVar,
+				A: t.operand(work.e.LeftChild(), work.polarity),
+				B: t.operand(work.e.RightChild(), work.polarity),
+			}
+		case *operators.NConjunction:
+			exprSplit = &operators.NConjunction{Operands: t.operands(work.e.(*operators.NConjunction).Operands, work.polarity)}
+		case *operators.NDisjunction:
+			exprSplit = &operators.NDisjunction{Operands: t.operands(work.e.(*operators.NDisjunction).Operands, work.polarity)}
+		case *operators.Implication:
+			exprSplit = &operators.Implication{
+				A: t.operand(work.e.LeftChild(), work.polarity.flip()),
+				B: t.operand(work.e.RightChild(), work.polarity),
+			}
+		case *operators.ExclusiveDisjunction, *operators.Biimplication:
+			if t.opts.NativeXor {
+				// v ⟷ (t1 ⊗ ... ⊗ tn) is the xor clause v ⊗ t1 ⊗ ... ⊗ tn = false
+				x := XorClause{Terms: []operators.Term{work.v}}
+				t.xorOperands(work.e, &x)
+				t.xors = append(t.xors, x)
+				continue
+			}
+			exprSplit = work.e.(operators.Operator).Join(t.operand(work.e.LeftChild(), both), t.operand(work.e.RightChild(), both))
+		case *operators.Choice:
+			// an if-then-else on a variable, e.g. a node of a BDD
+			exprSplit = &operators.Choice{
+				True:  t.operand(work.e.LeftChild(), work.polarity),
+				Var:   work.e.(*operators.Choice).Var,
+				False: t.operand(work.e.RightChild(), work.polarity),
 			}
-			queue = append(queue, [2]operators.Expression{leftVar, work[1].LeftChild()})
-			queue = append(queue, [2]operators.Expression{rightVar, work[1].RightChild()})
 		default:
 			panic("unrecognized operator type in Tseitin transformation")
 		}
 
 		// convert simple clause to cnf
-		cnf := CNF(operators.Biimplies(work[0], exprSplit))
-		result = append(result, cnf...)
+		cnf := CNF(operators.Biimplies(work.v, exprSplit))
+		result = append(result, polarityClauses(work.v, work.polarity, cnf)...)
+	}
+
+	return result, t.xors
+}
+
+// operand returns the term that represents e in polarity p. Variables are used directly, a negation is the negated
+// operand of its subexpression, and every other subexpression is represented by its shared auxiliary variable.
+// The subexpression is queued for the polarities in which it has not been encoded yet.
+func (t *tseitin) operand(e operators.Expression, p polarity) operators.Term {
+	switch e.(type) {
+	case operators.Variable:
+		return e.(operators.Term)
+	case *operators.Negation:
+		return t.operand(e.RightChild(), p.flip()).Negate()
+	case *operators.ExclusiveDisjunction, *operators.Biimplication:
+		if t.opts.NativeXor {
+			// xor clauses are equivalences, so they are encoded in both polarities at once
+			p = both
+		}
+	}
+
+	id := t.id(e)
+	shared, ok := t.vars[id]
+	if !ok {
+		shared = &sharedVar{v: operators.IncVar().(operators.Variable)}
+		t.vars[id] = shared
+	}
+
+	if missing := p &^ shared.polarity; missing != 0 {
+		shared.polarity |= missing
+		t.queue = append(t.queue, tseitinItem{shared.v, e, missing})
+	}
+
+	return shared.v
+}
+
+// operands returns the operands of an n-ary operator in polarity p
+func (t *tseitin) operands(operands []operators.Expression, p polarity) []operators.Expression {
+	result := make([]operators.Expression, len(operands))
+	for i, o := range operands {
+		result[i] = t.operand(o, p)
+	}
+	return result
+}
+
+// id returns the identifier of the structure of n, which is equal for structurally identical nodes.
+// Cardinality and pseudo-Boolean constraints are only identified by their pointer.
+func (t *tseitin) id(n operators.Node) int {
+	if n == nil {
+		return 0
+	}
+	if id, ok := t.ids[n]; ok {
+		return id
+	}
+
+	key := nodeKey{kind: reflect.TypeOf(n)}
+	switch n.(type) {
+	case operators.Variable, operators.Constant:
+		key.name = n.String()
+	case *operators.Cardinality, *operators.PseudoBoolean:
+		t.next++
+		t.ids[n] = t.next
+		return t.next
+	case *operators.Choice:
+		key.choice = t.id(n.(*operators.Choice).Var)
+		key.left, key.right = t.id(n.LeftChild()), t.id(n.RightChild())
+	case *operators.NConjunction:
+		key.name = t.operandIds(n.(*operators.NConjunction).Operands)
+	case *operators.NDisjunction:
+		key.name = t.operandIds(n.(*operators.NDisjunction).Operands)
+	default:
+		key.left, key.right = t.id(n.LeftChild()), t.id(n.RightChild())
+	}
+
+	id, ok := t.keys[key]
+	if !ok {
+		t.next++
+		id = t.next
+		t.keys[key] = id
+	}
+	t.ids[n] = id
+	return id
+}
+
+// polarityClauses removes the clauses of the bi-implication of v that are not needed for polarity p: clauses with ¬v
+// encode the direction v → e, and clauses with v encode the direction e → v
+func polarityClauses(v operators.Term, p polarity, cnf operators.CNF) operators.CNF {
+	if p == both {
+		return cnf
 	}
 
+	result := cnf[:0]
+	for _, clause := range cnf {
+		if p&positive == 0 && clause.HasTerm(v.Negate()) {
+			continue
+		}
+		if p&negative == 0 && clause.HasTerm(v) {
+			continue
+		}
+		result = append(result, clause)
+	}
 	return result
 }
+
+// operandIds identifies the operands of an n-ary operator by the list of their identifiers
+func (t *tseitin) operandIds(operands []operators.Expression) string {
+	ids := make([]int, len(operands))
+	for i, o := range operands {
+		ids[i] = t.id(o)
+	}
+	return fmt.Sprint(ids)
+}
+
+// xorOperands adds the operands of nested exclusive disjunctions, bi-implications and negations in e to the xor
+// clause x. Every other subexpression is added as its operand, and is queued for the Tseitin transformation.
+func (t *tseitin) xorOperands(e operators.Expression, x *XorClause) {
+	switch e.(type) {
+	case operators.Constant:
+		x.Parity = x.Parity != e.(operators.Constant).Value()
+	case operators.Variable:
+		x.Terms = append(x.Terms, e.(operators.Term))
+	case *operators.Negation:
+		x.Parity = !x.Parity
+		t.xorOperands(e.RightChild(), x)
+	case *operators.ExclusiveDisjunction:
+		t.xorOperands(e.LeftChild(), x)
+		t.xorOperands(e.RightChild(), x)
+	case *operators.Biimplication:
+		// a ⟷ b is ¬(a ⊗ b)
+		x.Parity = !x.Parity
+		t.xorOperands(e.LeftChild(), x)
+		t.xorOperands(e.RightChild(), x)
+	default:
+		x.Terms = append(x.Terms, t.operand(e, both))
+	}
+}
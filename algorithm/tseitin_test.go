@@ -1,9 +1,11 @@
 package algorithm
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/timbeurskens/gobdd/bdd_test"
 	"github.com/timbeurskens/gobdd/operators/bdd"
+	"strings"
 	"testing"
 
 	op "github.com/timbeurskens/gobdd/operators"
@@ -39,7 +41,7 @@ func TestTransformTseitin(t *testing.T) {
 			pruned := PruneUnary(e)
 			nnf := NNF(e)
 			cnf := TransformTseitin(nnf)
-			cdclSat, _ := CDCL(cnf)
+			cdclSat := CDCL(cnf).Sat()
 			resBdd := FromExpression(pruned)
 			satBdd := bdd.Sat(resBdd)
 			be.Assert("cdcl and bdd are SAT equivalent", satBdd == cdclSat)
@@ -48,6 +50,100 @@ func TestTransformTseitin(t *testing.T) {
 	}
 }
 
+func TestTransformTseitinOperators(t *testing.T) {
+	// expressions that are not in NNF, with an equivalent expression in NNF
+	ite := &op.Choice{True: op.And(b, c), Var: a, False: op.Xor(b, d)}
+	pairs := [][2]op.Expression{
+		{op.Not(op.Implies(a, op.Xor(b, c))), op.And(a, op.Or(op.And(b, c), op.And(op.Not(b), op.Not(c))))},
+		{op.Biimplies(op.Implies(a, b), op.Xor(c, d)), op.Or(op.And(op.Or(op.Not(a), b), op.Or(op.And(c, op.Not(d)), op.And(op.Not(c), d))),
+			op.And(op.And(a, op.Not(b)), op.Or(op.And(c, d), op.And(op.Not(c), op.Not(d)))))},
+		{op.Not(op.Not(op.And(a, b))), op.And(a, b)},
+		{ite, op.Or(op.And(a, op.And(b, c)), op.And(op.Not(a), op.Or(op.And(b, op.Not(d)), op.And(op.Not(b), d))))},
+		{op.Not(ite), op.Or(op.And(a, op.Or(op.Not(b), op.Not(c))), op.And(op.Not(a), op.Or(op.And(b, d), op.And(op.Not(b), op.Not(d)))))},
+	}
+
+	for i, pair := range pairs {
+		t.Run(fmt.Sprintf("Expression %d is encoded without normalization", i), func(t *testing.T) {
+			be := bdd_test.Bench{T: t}
+
+			s := NewSolver()
+			s.AddCNF(TransformTseitin(pair[0]))
+
+			// the cnf has a model under every assignment of the variables that satisfies the expression
+			for mask := 0; mask < 16; mask++ {
+				assumptions := make([]op.Term, 4)
+				literals := make([]op.Expression, 4)
+				for j, v := range []op.Variable{a, b, c, d} {
+					if mask>>j&1 == 1 {
+						assumptions[j] = v
+					} else {
+						assumptions[j] = v.Negate()
+					}
+					literals[j] = assumptions[j]
+				}
+
+				expected := bdd.Sat(FromExpression(PruneUnary(op.And(pair[1], op.And(literals...)))))
+				be.AssertInfo("cnf agrees with the expression", s.Solve(assumptions...) == expected, assumptions)
+			}
+		})
+	}
+}
+
+func TestTransformTseitinPolarity(t *testing.T) {
+	for i, e := range expressions {
+		t.Run(fmt.Sprintf("Polarity encoding of expression %d agrees with the expression", i), func(t *testing.T) {
+			be := bdd_test.Bench{T: t}
+
+			full := TransformTseitin(e)
+			cnf, _ := TransformTseitinWith(e, TseitinOptions{Polarity: true})
+			be.AssertInfo("polarity encoding does not add clauses", len(cnf) <= len(full), len(cnf), len(full))
+
+			s := NewSolver()
+			s.AddCNF(cnf)
+
+			for mask := 0; mask < 16; mask++ {
+				assumptions := make([]op.Term, 4)
+				literals := make([]op.Expression, 4)
+				for j, v := range []op.Variable{a, b, c, d} {
+					if mask>>j&1 == 1 {
+						assumptions[j] = v
+					} else {
+						assumptions[j] = v.Negate()
+					}
+					literals[j] = assumptions[j]
+				}
+
+				expected := bdd.Sat(FromExpression(PruneUnary(op.And(e, op.And(literals...)))))
+				be.AssertInfo("cnf agrees with the expression", s.Solve(assumptions...) == expected, assumptions)
+			}
+		})
+	}
+
+	t.Run("Polarity encoding of a conjunction of disjunctions halves the clauses", func(t *testing.T) {
+		be := bdd_test.Bench{T: t}
+		e := op.And(op.Or(a, b), op.Or(c, d), op.Or(a, d))
+
+		full := TransformTseitin(e)
+		cnf, _ := TransformTseitinWith(e, TseitinOptions{Polarity: true})
+		be.AssertInfo("polarity encoding has at most half of the clauses", 2*len(cnf) <= len(full)+1, len(cnf), len(full))
+	})
+}
+
+func TestTransformTseitinSharing(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	// the exclusive disjunctions are structurally identical, but different pointers
+	e := op.And(op.Xor(a, b), op.Or(op.Not(op.Xor(a, b)), c))
+	cnf := TransformTseitin(e)
+
+	// a, b, c and one auxiliary variable for the conjunction, the disjunction and the exclusive disjunction
+	be.AssertInfo("identical subexpressions share a variable", len(op.Variables(cnf)) == 6, op.Variables(cnf))
+	be.Assert("cnf is satisfiable", CDCL(cnf).Sat())
+
+	// a leaf is used directly
+	be.AssertInfo("a variable is not encoded", len(TransformTseitin(op.Not(a))) == 1, TransformTseitin(op.Not(a)))
+}
+
 func TestNNF(t *testing.T) {
 	for i, e := range expressions {
 		t.Run(fmt.Sprintf("Expression %d is equal to NNF of expression", i), func(t *testing.T) {
@@ -58,3 +154,71 @@ func TestNNF(t *testing.T) {
 		})
 	}
 }
+
+func TestNNFSharing(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	vars := make([]op.Expression, 24)
+	for i := range vars {
+		vars[i] = op.IVar(i)
+	}
+
+	// normalization of the chain copies both operands of every exclusive disjunction, unless they are shared
+	nnf := NNF(op.Xor(vars...))
+	be.AssertInfo("nnf of a chain of exclusive disjunctions is shared", op.Size(nnf) <= 8*len(vars), op.Size(nnf))
+	be.AssertInfo("pruning preserves sharing", op.Size(PruneUnary(nnf)) <= 12*len(vars), op.Size(PruneUnary(nnf)))
+}
+
+func TestTransformTseitinNary(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	e := op.Or(a, b, c, d)
+	_, ok := e.(*op.NDisjunction)
+	be.Assert("a disjunction of four operands is n-ary", ok)
+
+	// the root, one wide clause and a binary clause for every operand
+	cnf := TransformTseitin(e)
+	be.AssertInfo("an n-ary disjunction is a single clause", len(cnf) == 6, cnf)
+
+	cnf, _ = TransformTseitinWith(e, TseitinOptions{Polarity: true})
+	be.AssertInfo("a positive n-ary disjunction only needs the wide clause", len(cnf) == 2, cnf)
+
+	nnf := NNF(op.Not(op.And(a, b, c)))
+	dis, ok := nnf.(*op.NDisjunction)
+	be.AssertInfo("DeMorgan turns an n-ary conjunction into an n-ary disjunction", ok && len(dis.Operands) == 3, nnf)
+}
+
+func TestFromExpressionEmptyNary(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	be.AssertTautology("an empty n-ary conjunction is true", NewBuilder().FromExpression(&op.NConjunction{}))
+	be.AssertUnsat("an empty n-ary disjunction is false", NewBuilder().FromExpression(&op.NDisjunction{}))
+	be.Assert("the cnf of an empty n-ary conjunction is satisfiable", CDCL(TransformTseitin(&op.NConjunction{})).Sat())
+	be.Assert("the cnf of an empty n-ary disjunction is unsatisfiable", !CDCL(TransformTseitin(&op.NDisjunction{})).Sat())
+
+	be.Assert("nnf of an empty n-ary conjunction is true", NNF(&op.NConjunction{}) == op.Cons(true))
+	be.Assert("nnf of an empty n-ary disjunction is false", NNF(&op.NDisjunction{}) == op.Cons(false))
+	be.Assert("nnf of a negated empty n-ary disjunction is true", NNF(op.Not(&op.NDisjunction{})) == op.Cons(true))
+	be.Assert("the cnf of the nnf of an empty n-ary conjunction is satisfiable", CDCL(TransformTseitin(NNF(&op.NConjunction{}))).Sat())
+	be.Assert("the cnf of the nnf of an empty n-ary disjunction is unsatisfiable", !CDCL(TransformTseitin(NNF(&op.NDisjunction{}))).Sat())
+}
+
+func TestTransformTseitinDIMACS(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	var out bytes.Buffer
+	err := op.WriteDIMACS(&out, TransformTseitin(op.And(a, op.Or(b, c))))
+	be.AssertInfo("cnf is written without errors", err == nil, err)
+
+	// a, b, c and the auxiliary variables of the conjunction and the disjunction are numbered densely
+	be.AssertInfo("header counts five variables", bytes.Contains(out.Bytes(), []byte("p cnf 5 7\n")), out.String())
+
+	// the numbering only depends on the cnf, not on files that have been read before
+	_, err = op.ParseDIMACS(strings.NewReader("p cnf 700000 1\n700000 0\n"))
+	be.AssertInfo("dimacs is parsed without errors", err == nil, err)
+
+	out.Reset()
+	err = op.WriteDIMACS(&out, TransformTseitin(op.And(a, op.Or(b, c))))
+	be.AssertInfo("cnf is written without errors", err == nil, err)
+	be.AssertInfo("header still counts five variables", bytes.Contains(out.Bytes(), []byte("p cnf 5 7\n")), out.String())
+}
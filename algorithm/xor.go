@@ -0,0 +1,391 @@
+package algorithm
+
+import (
+	"context"
+	"math/bits"
+
+	"github.com/timbeurskens/gobdd/operators"
+)
+
+// XorClause is the constraint Terms[0] ⊗ Terms[1] ⊗ ... ⊗ Terms[n-1] = Parity.
+// An empty xor clause with parity true can never be satisfied.
+type XorClause struct {
+	Terms  []operators.Term
+	Parity bool
+}
+
+// CNF returns the clauses that forbid every assignment of the terms with the wrong parity.
+// The number of clauses is exponential in the number of terms, so only short xor clauses should be expanded.
+func (x XorClause) CNF() operators.CNF {
+	cnf := make(operators.CNF, 0, 1<<len(x.Terms)/2)
+
+	for mask := 0; mask < 1<<len(x.Terms); mask++ {
+		// the clause excludes the assignment where exactly the terms in mask are true
+		if bits.OnesCount(uint(mask))%2 == 1 == x.Parity {
+			continue
+		}
+
+		clause := make(operators.NClause, len(x.Terms))
+		for i, t := range x.Terms {
+			if mask>>i&1 == 1 {
+				clause[i] = t.Negate()
+			} else {
+				clause[i] = t
+			}
+		}
+		cnf = appendClause(cnf, clause...)
+	}
+
+	return cnf
+}
+
+// CDCLXor solves cnf together with xor clauses. The xor clauses are not expanded into CNF, but propagated by
+// Gauss-Jordan elimination in the Solver, see Solver.AddXor.
+func CDCLXor(cnf operators.CNF, xors []XorClause) Result {
+	s := NewSolver()
+	s.AddCNF(cnf)
+	for _, x := range xors {
+		s.AddXor(x.Terms, x.Parity)
+	}
+
+	status := s.SolveContext(context.Background())
+
+	return Result{
+		Status: status,
+		Model:  s.Model(),
+		Stats:  s.Stats(),
+	}
+}
+
+// xorRow is a row of the Gauss-Jordan matrix: the exclusive disjunction of the variables in its columns is parity.
+// The basic variable of a row occurs in no other row.
+type xorRow struct {
+	bits   []uint64
+	parity bool
+	basic  int
+	// changed is true iff the row is in gaussMatrix.changed
+	changed bool
+}
+
+func (r *xorRow) has(column int) bool {
+	return r.bits[column/64]>>(column%64)&1 == 1
+}
+
+func (r *xorRow) flip(column int) {
+	r.bits[column/64] ^= 1 << (column % 64)
+}
+
+// add adds other to r, modulo 2
+func (r *xorRow) add(other *xorRow) {
+	for i, w := range other.bits {
+		r.bits[i] ^= w
+	}
+	r.parity = r.parity != other.parity
+}
+
+func (r *xorRow) empty() bool {
+	for _, w := range r.bits {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// gaussMatrix keeps the xor clauses of a Solver in reduced row echelon form.
+// The current assignment of the variables in the matrix is mirrored in bitsets, such that the number of unassigned
+// variables and the parity of the assigned variables of a row are counted a word at a time.
+//
+// The matrix maintains the invariant of Han and Jiang (2012): the basic variable of a row that has at least two
+// unassigned variables is unassigned. If the basic variable gets assigned, another unassigned variable of the row
+// becomes basic and is eliminated from all other rows. A row with one unassigned variable implies it, and under this
+// invariant, these rows find every literal that is implied by the xor clauses together.
+type gaussMatrix struct {
+	rows []*xorRow
+
+	// variable is the solver variable of every column, column is the column of every solver variable or -1
+	variable []int
+	column   []int
+
+	assigned []uint64
+	values   []uint64
+
+	pending []*xorRow
+	// changed holds the rows that were changed by pivot since the last backtrack. Under the assignment after
+	// backtracking, such a row can imply a literal that none of the rows implied before, so it is checked again.
+	changed []*xorRow
+}
+
+func (g *gaussMatrix) columnOf(v int) int {
+	if v < len(g.column) {
+		return g.column[v]
+	}
+	return -1
+}
+
+// addColumn adds solver variable v with its current value to the matrix
+func (g *gaussMatrix) addColumn(v int, value lbool) int {
+	for len(g.column) <= v {
+		g.column = append(g.column, -1)
+	}
+
+	c := len(g.variable)
+	g.variable = append(g.variable, v)
+	g.column[v] = c
+
+	if c/64 >= len(g.assigned) {
+		g.assigned = append(g.assigned, 0)
+		g.values = append(g.values, 0)
+		for _, r := range g.rows {
+			r.bits = append(r.bits, 0)
+		}
+	}
+
+	g.set(c, value)
+	return c
+}
+
+func (g *gaussMatrix) set(column int, value lbool) {
+	w, bit := column/64, uint64(1)<<(column%64)
+	switch value {
+	case lUndef:
+		g.assigned[w] &^= bit
+		g.values[w] &^= bit
+	case lTrue:
+		g.assigned[w] |= bit
+		g.values[w] |= bit
+	case lFalse:
+		g.assigned[w] |= bit
+		g.values[w] &^= bit
+	}
+}
+
+func (g *gaussMatrix) isAssigned(column int) bool {
+	return g.assigned[column/64]>>(column%64)&1 == 1
+}
+
+// status returns the number of unassigned variables in r, the first unassigned column and the parity of the
+// assigned variables that are true
+func (g *gaussMatrix) status(r *xorRow) (unassigned, free int, parity bool) {
+	free = -1
+	for i, w := range r.bits {
+		if u := w &^ g.assigned[i]; u != 0 {
+			if free < 0 {
+				free = i*64 + bits.TrailingZeros64(u)
+			}
+			unassigned += bits.OnesCount64(u)
+		}
+		if bits.OnesCount64(w&g.values[i])%2 == 1 {
+			parity = !parity
+		}
+	}
+	return
+}
+
+// pivot makes column the basic variable of r, and eliminates it from every other row.
+// The other rows that change are queued to be checked.
+func (g *gaussMatrix) pivot(r *xorRow, column int) {
+	r.basic = column
+	for _, other := range g.rows {
+		if other != r && other.has(column) {
+			other.add(r)
+			g.pending = append(g.pending, other)
+			if !other.changed {
+				other.changed = true
+				g.changed = append(g.changed, other)
+			}
+		}
+	}
+}
+
+// repair restores the invariant after backtracking: assignments are undone, but rows that were modified at a
+// higher decision level may now have an assigned basic variable and several unassigned variables.
+// The changed rows are queued in pending, to be checked by the next call to propagate.
+func (g *gaussMatrix) repair() {
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.rows {
+			if !g.isAssigned(r.basic) {
+				continue
+			}
+			if unassigned, free, _ := g.status(r); unassigned >= 2 {
+				g.pivot(r, free)
+				changed = true
+			}
+		}
+	}
+
+	g.pending = g.pending[:0]
+	for _, r := range g.changed {
+		r.changed = false
+		g.pending = append(g.pending, r)
+	}
+	g.changed = g.changed[:0]
+}
+
+// AddXor adds the xor clause terms[0] ⊗ terms[1] ⊗ ... ⊗ terms[n-1] = parity to the solver.
+// Xor clauses are not expanded into CNF, but kept as rows of a matrix on which Gauss-Jordan elimination propagates
+// every literal that is implied by the xor clauses together. The reasons of these literals are clauses that are
+// derived from the xor clauses, so a DRAT proof does not cover them.
+// It returns false iff the clauses in the solver are known to be unsatisfiable.
+func (s *Solver) AddXor(terms []operators.Term, parity bool) bool {
+	if !s.ok {
+		return false
+	}
+
+	g := &s.gauss
+
+	columns := make([]int, 0, len(terms))
+	for _, t := range terms {
+		lit, c := s.literal(t)
+		if c != nil {
+			parity = parity != c.Value()
+			continue
+		}
+		if lit.negative() {
+			parity = !parity
+		}
+
+		v := lit.variable()
+		column := g.columnOf(v)
+		if column < 0 {
+			column = g.addColumn(v, s.assigns[v])
+		}
+		columns = append(columns, column)
+	}
+
+	// variables that occur twice cancel out
+	row := &xorRow{bits: make([]uint64, len(g.assigned)), parity: parity}
+	for _, column := range columns {
+		row.flip(column)
+	}
+
+	for _, other := range g.rows {
+		if row.has(other.basic) {
+			row.add(other)
+		}
+	}
+
+	if row.empty() {
+		if row.parity {
+			s.ok = false
+		}
+		return s.ok
+	}
+
+	// prefer an unassigned basic variable
+	_, free, _ := g.status(row)
+	if free < 0 {
+		for column := range g.variable {
+			if row.has(column) {
+				free = column
+				break
+			}
+		}
+	}
+	g.pivot(row, free)
+	g.rows = append(g.rows, row)
+	g.pending = g.pending[:0]
+
+	for _, r := range g.rows {
+		if s.checkXor(r) != nil {
+			s.ok = false
+			return false
+		}
+	}
+	g.pending = g.pending[:0]
+
+	if s.propagate() != nil {
+		s.ok = false
+	}
+
+	return s.ok
+}
+
+// propagateXor checks every row that contains variable v, which has just been assigned.
+// It returns a conflicting clause if a row is falsified.
+func (s *Solver) propagateXor(v int) *clause {
+	g := &s.gauss
+
+	column := g.columnOf(v)
+	if column < 0 {
+		return nil
+	}
+
+	g.pending = g.pending[:0]
+	for _, r := range g.rows {
+		if r.has(column) {
+			g.pending = append(g.pending, r)
+		}
+	}
+
+	return s.checkPending()
+}
+
+// checkPending checks the queued rows of the matrix, including the rows that change while they are checked.
+// It returns a conflicting clause if a row is falsified.
+func (s *Solver) checkPending() *clause {
+	g := &s.gauss
+
+	for len(g.pending) > 0 {
+		r := g.pending[len(g.pending)-1]
+		g.pending = g.pending[:len(g.pending)-1]
+
+		if confl := s.checkXor(r); confl != nil {
+			g.pending = g.pending[:0]
+			return confl
+		}
+	}
+
+	return nil
+}
+
+// checkXor propagates the last unassigned variable of r, or returns a conflicting clause if every variable of r is
+// assigned with the wrong parity. If the basic variable of r is assigned while r has other unassigned variables, one
+// of them becomes basic instead.
+func (s *Solver) checkXor(r *xorRow) *clause {
+	g := &s.gauss
+
+	unassigned, free, parity := g.status(r)
+
+	switch {
+	case unassigned == 0:
+		if parity == r.parity {
+			return nil
+		}
+		return &clause{lits: s.xorReason(r, undefLiteral)}
+	case unassigned == 1:
+		v := g.variable[free]
+		// the variable completes the parity of the row
+		lit := makeLiteral(v, parity == r.parity)
+		s.enqueue(lit, &clause{lits: s.xorReason(r, lit)})
+	case g.isAssigned(r.basic):
+		g.pivot(r, free)
+	}
+
+	return nil
+}
+
+// xorReason returns the clause that implies lit by r: lit, followed by the negation of the current assignment of
+// every other variable in r. Without lit, the clause is falsified by the assignment.
+func (s *Solver) xorReason(r *xorRow, lit literal) []literal {
+	g := &s.gauss
+
+	lits := make([]literal, 0, 4)
+	if lit != undefLiteral {
+		lits = append(lits, lit)
+	}
+
+	for i, w := range r.bits {
+		for w != 0 {
+			column := i*64 + bits.TrailingZeros64(w)
+			w &= w - 1
+
+			if v := g.variable[column]; lit == undefLiteral || v != lit.variable() {
+				lits = append(lits, makeLiteral(v, s.assigns[v] == lTrue))
+			}
+		}
+	}
+
+	return lits
+}
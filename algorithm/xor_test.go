@@ -0,0 +1,209 @@
+package algorithm
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	"github.com/timbeurskens/gobdd/operators"
+	"github.com/timbeurskens/gobdd/operators/bdd"
+)
+
+func TestXorClauseCNF(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c := operators.Var("a"), operators.Var("b"), operators.Var("c")
+	vars := []operators.Variable{a, b, c}
+
+	odd := XorClause{Terms: []operators.Term{a, b.Negate(), c}, Parity: true}
+	be.AssertInfo("a xor not b xor c has 4 clauses", len(odd.CNF()) == 4, odd.CNF())
+	be.AssertInfo("a xor not b xor c has 4 models", CountModels(odd.CNF(), vars).Int64() == 4, odd.CNF())
+
+	be.Assert("the empty xor clause with parity true is unsat", !CDCL(XorClause{Parity: true}.CNF()).Sat())
+	be.Assert("the empty xor clause with parity false is sat", CDCL(XorClause{}.CNF()).Sat())
+}
+
+func TestSolverXor(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	x := make([]operators.Term, 8)
+	for i := range x {
+		x[i] = operators.IVar(i + 1)
+	}
+
+	s := NewSolver()
+	be.Assert("x1 xor x2 is sat", s.AddXor([]operators.Term{x[0], x[1]}, true))
+	be.Assert("x2 xor x3 is sat", s.AddXor([]operators.Term{x[1], x[2]}, true))
+	be.Assert("x1 xor x3 is unsat after elimination", !s.AddXor([]operators.Term{x[0], x[2]}, true))
+
+	// a chain x1 ⊗ x2 = 1, x2 ⊗ x3 = 1, ..., x7 ⊗ x8 = 1 determines every variable once x1 is assumed
+	s = NewSolver()
+	for i := 0; i+1 < len(x); i++ {
+		s.AddXor([]operators.Term{x[i], x[i+1]}, true)
+	}
+
+	be.Assert("the chain is sat under x1", s.Solve(x[0]))
+	be.AssertInfo("x1 is propagated through the chain without decisions", s.Stats().Decisions <= 1, s.Stats())
+	for i := range x {
+		be.AssertInfo(fmt.Sprintf("x%d alternates", i+1), s.Model()[x[i].Variable()] == (i%2 == 0), s.Model())
+	}
+
+	// x1, x3 and x5 are equal in the chain, so x1 ⊗ x3 ⊗ x5 = 0 forces x1 to be false
+	s.AddXor([]operators.Term{x[0], x[2], x[4]}, false)
+	be.Assert("the even sum of x1, x3 and x5 is unsat under x1", !s.Solve(x[0]))
+	be.AssertInfo("x1 is the core", len(s.Core()) == 1 && s.Core()[0] == x[0], s.Core())
+	be.Assert("the even sum of x1, x3 and x5 is sat under not x1", s.Solve(x[0].Negate()))
+}
+
+func TestSolverXorClauses(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c, d := operators.Var("a"), operators.Var("b"), operators.Var("c"), operators.Var("d")
+
+	// a ⊗ b ⊗ c = 1 and c ⊗ d = 0, together with clauses that exclude all but one model
+	cnf := operators.CNF{
+		operators.NClause{a, b},
+		operators.NClause{a.Negate(), b.Negate()},
+		operators.NClause{c, d.Negate(), a},
+	}
+	xors := []XorClause{
+		{Terms: []operators.Term{a, b, c}, Parity: true},
+		{Terms: []operators.Term{c, d}},
+	}
+
+	result := CDCLXor(cnf, xors)
+	be.Assert("clauses and xor clauses are sat", result.Sat())
+
+	full := append(operators.CNF{}, cnf...)
+	for _, x := range xors {
+		full = append(full, x.CNF()...)
+	}
+	be.AssertInfo("the model satisfies the expanded xor clauses", bdd.Sat(FromExpression(PruneUnary(full.Expr()))), result.Model)
+
+	for _, clause := range full {
+		satisfied := false
+		for _, term := range clause.Terms() {
+			_, negative := term.(*operators.Negation)
+			satisfied = satisfied || result.Model[term.Variable()] != negative
+		}
+		be.AssertInfo("the model satisfies every clause", satisfied, clause, result.Model)
+	}
+}
+
+func TestTransformTseitinXor(t *testing.T) {
+	for i, e := range expressions {
+		t.Run(fmt.Sprintf("Expression %d is equal to native xor CNF of expression", i), func(t *testing.T) {
+			be := bdd_test.Bench{T: t}
+
+			// the expression does not have to be normalized
+			cnf, xors := TransformTseitinWith(e, TseitinOptions{NativeXor: true})
+			full := append(operators.CNF{}, cnf...)
+			for _, x := range xors {
+				full = append(full, x.CNF()...)
+			}
+
+			vars := []operators.Variable{a, b, c, d}
+			expected := CountModels(TransformTseitin(NNF(e)), vars)
+			count := CountModels(full, vars)
+
+			be.AssertInfo("xor clauses and clauses have the models of the expression", count.Cmp(expected) == 0, count, expected)
+			be.Assert("cdcl with xor clauses and bdd are SAT equivalent", CDCLXor(cnf, xors).Sat() == bdd.Sat(FromExpression(PruneUnary(e))))
+		})
+	}
+}
+
+func TestTransformTseitinXorChain(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	x := make([]operators.Expression, 40)
+	reversed := make([]operators.Expression, len(x))
+	for i := range x {
+		x[i] = operators.IVar(i + 1)
+		reversed[len(x)-1-i] = x[i]
+	}
+
+	// both chains have the same parity, which is hard to see for resolution but trivial for Gauss-Jordan elimination
+	e := operators.Not(operators.Biimplies(operators.Xor(x...), operators.Xor(reversed...)))
+
+	cnf, xors := TransformTseitinWith(e, TseitinOptions{NativeXor: true})
+	be.AssertInfo("nested exclusive disjunctions are flattened into a single xor clause", len(xors) == 1, xors)
+
+	result := CDCLXor(cnf, xors)
+	be.Assert("different parities of the same variables are unsat", !result.Sat())
+	be.AssertInfo("unsat without conflicts", result.Stats.Conflicts == 0, result.Stats)
+}
+
+// TestSolverXorBackjump checks that the matrix propagates every implied literal after backjumping, also when the rows
+// were changed at the levels that are undone
+func TestSolverXorBackjump(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	rng := rand.New(rand.NewSource(1))
+	const n = 8
+
+	for instance := 0; instance < 500; instance++ {
+		s := NewSolver()
+		vars := make([]int, n)
+		for i := range vars {
+			lit, _ := s.literal(operators.IVar(i + 1))
+			vars[i] = lit.variable()
+		}
+
+		rows := make([][]int, 4)
+		parities := make([]bool, len(rows))
+		for i := range rows {
+			var terms []operators.Term
+			for _, j := range rng.Perm(n)[:3+rng.Intn(2)] {
+				rows[i] = append(rows[i], j)
+				terms = append(terms, operators.IVar(j+1))
+			}
+			parities[i] = rng.Intn(2) == 0
+			s.AddXor(terms, parities[i])
+		}
+		if !s.ok {
+			continue
+		}
+
+		// decide until a conflict or depth 4, then backjump to a random lower level
+		for s.decisionLevel() < 4 {
+			v := vars[rng.Intn(n)]
+			if s.assigns[v] != lUndef {
+				continue
+			}
+			s.newDecisionLevel()
+			s.enqueue(makeLiteral(v, rng.Intn(2) == 0), nil)
+			if s.propagate() != nil {
+				break
+			}
+		}
+		s.cancelUntil(rng.Intn(s.decisionLevel() + 1))
+		if s.propagate() != nil {
+			continue
+		}
+
+		// a literal is implied iff only one of its values is consistent with the xor clauses and the assignment
+		possible := make([][2]bool, n)
+		for m := 0; m < 1<<n; m++ {
+			consistent := true
+			for i, v := range vars {
+				if value := m>>i&1 == 1; s.assigns[v] != lUndef && (s.assigns[v] == lTrue) != value {
+					consistent = false
+				}
+			}
+			for i, row := range rows {
+				parity := false
+				for _, j := range row {
+					parity = parity != (m>>j&1 == 1)
+				}
+				consistent = consistent && parity == parities[i]
+			}
+			if consistent {
+				for i := range vars {
+					possible[i][m>>i&1] = true
+				}
+			}
+		}
+
+		for i, v := range vars {
+			implied := possible[i][0] != possible[i][1]
+			be.AssertInfo(fmt.Sprintf("implied variable %d is assigned in instance %d", i+1, instance), !implied || s.assigns[v] != lUndef, rows)
+		}
+	}
+}
@@ -108,6 +108,34 @@ func TestModel(t *testing.T) {
 	b.Assert("model should have p=true", model[p])
 }
 
+func TestImplied(t *testing.T) {
+	b := bdd_test.Bench{T: t}
+
+	p, q, r, s := Var("p"), Var("q"), Var("r"), Var("s")
+
+	// p implies q, q excludes r, and s is free
+	expr := And(Implies(p, q), Not(And(q, r)))
+
+	implied, sat := bdd.Implied(algorithm.FromExpression(algorithm.PruneUnary(expr)))
+	b.AssertInfo("nothing is implied without choices", sat && len(implied) == 0, implied)
+
+	implied, sat = bdd.Implied(algorithm.FromExpression(algorithm.PruneUnary(And(expr, p, Or(s, Not(s))))))
+	b.AssertInfo("choosing p implies p and q, and excludes r", sat && len(implied) == 3, implied)
+	for _, lit := range implied {
+		switch lit.Variable() {
+		case p, q:
+			b.AssertInfo("p and q are true", lit == Term(lit.Variable()), lit)
+		case r:
+			b.AssertInfo("r is false", lit.Variable().Negate().TermEquivalent(lit), lit)
+		default:
+			b.AssertInfo("s is free", false, lit)
+		}
+	}
+
+	implied, sat = bdd.Implied(algorithm.FromExpression(algorithm.PruneUnary(And(expr, p, r))))
+	b.AssertInfo("unsatisfiable bdd implies nothing", !sat && implied == nil, implied)
+}
+
 func TestSize(t *testing.T) {
 	b := bdd_test.Bench{T: t}
 
@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"reflect"
+	"strings"
 	"unsafe"
 
 	"github.com/timbeurskens/gobdd/operators"
@@ -39,9 +40,30 @@ func PrintExpressiontree(e operators.Expression) string {
 	if _, ok := e.(operators.Constant); ok {
 		return e.String()
 	}
+	if _, ok := e.(*operators.Cardinality); ok {
+		return e.String()
+	}
+	if _, ok := e.(*operators.PseudoBoolean); ok {
+		return e.String()
+	}
+	if c, ok := e.(*operators.NConjunction); ok {
+		return printOperands(c.Operands, c.String())
+	}
+	if d, ok := e.(*operators.NDisjunction); ok {
+		return printOperands(d.Operands, d.String())
+	}
 	return fmt.Sprintf("(%s %s %s)", PrintExpressiontree(e.LeftChild()), e.String(), PrintExpressiontree(e.RightChild()))
 }
 
+// printOperands prints the operands of an n-ary operator, separated by the symbol of the operator
+func printOperands(operands []operators.Expression, symbol string) string {
+	printed := make([]string, len(operands))
+	for i, o := range operands {
+		printed[i] = PrintExpressiontree(o)
+	}
+	return fmt.Sprintf("(%s)", strings.Join(printed, " "+symbol+" "))
+}
+
 func DotExpressionTree(n operators.Expression) {
 	fmt.Println("digraph G {")
 	dotExpressionTreeRec(n)
@@ -50,6 +72,27 @@ func DotExpressionTree(n operators.Expression) {
 
 func dotExpressionTreeRec(n operators.Expression) string {
 	switch n.(type) {
+	case *operators.NConjunction, *operators.NDisjunction:
+		vname := fmt.Sprintf("%d", reflect.ValueOf(n).Pointer())
+		vlabel := n.String()
+
+		fmt.Printf("%s [label=\"%s\"]", vname, vlabel)
+		fmt.Println()
+
+		var operands []operators.Expression
+		if c, ok := n.(*operators.NConjunction); ok {
+			operands = c.Operands
+		} else {
+			operands = n.(*operators.NDisjunction).Operands
+		}
+
+		// an edge to every operand
+		for _, o := range operands {
+			fmt.Println(vname, "->", dotExpressionTreeRec(o))
+		}
+
+		return vname
+
 	case operators.Operator:
 		vname := fmt.Sprintf("%d", reflect.ValueOf(n).Pointer())
 		vlabel := n.String()
@@ -65,7 +108,8 @@ func dotExpressionTreeRec(n operators.Expression) string {
 
 		return vname
 
-	case operators.Constant, operators.Variable:
+	case operators.Constant, operators.Variable, *operators.Cardinality, *operators.PseudoBoolean:
+		// cardinality and pseudo-Boolean constraints are leafs of the expression tree
 		vname := fmt.Sprintf("%d", reflect.ValueOf(n).Pointer())
 		vlabel := n.String()
 
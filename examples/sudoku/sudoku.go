@@ -130,7 +130,12 @@ func solveBDD(expr Expression) (Model, bool) {
 
 	log.Println("Size of expression:", Size(expr))
 
-	tree := algorithm.FromExpression(expr)
+	builder := algorithm.NewBuilder()
+	builder.SetProgress(100000, func(stats algorithm.Stats) {
+		log.Printf("%d nodes created, %d cache hits", stats.Nodes, stats.CacheHits)
+	})
+
+	tree := builder.FromExpression(expr)
 
 	log.Println("Size of tree:", Size(tree))
 
@@ -146,9 +151,8 @@ func solveBDD(expr Expression) (Model, bool) {
 }
 
 func solveCDCL(expr Expression) (Model, bool) {
-	nnf := algorithm.NNF(expr)
-
-	cnf := algorithm.TransformTseitin(nnf)
+	// only a single model is needed, so the auxiliary variables do not have to be determined by the encoding
+	cnf, _ := algorithm.TransformTseitinWith(expr, algorithm.TseitinOptions{Polarity: true})
 
 	log.Printf("Solving %d clauses", len(cnf))
 
@@ -156,15 +160,9 @@ func solveCDCL(expr Expression) (Model, bool) {
 
 	log.Printf("%d variables", varCount)
 
-	if sat, m := algorithm.CDCL(cnf); sat {
-		if model, ok := bdd.FindModel(m); ok {
-			return model, true
-		} else {
-			return nil, false
-		}
-	} else {
-		return nil, false
-	}
+	result := algorithm.CDCL(cnf)
+
+	return result.Model, result.Sat()
 }
 
 func main() {
@@ -21,19 +21,47 @@ func TestNQueensCDCL(t *testing.T) {
 
 	log.Println(cnf)
 
-	sat, solution := algorithm.CDCL(cnf)
+	result := algorithm.CDCL(cnf)
 
-	b.Assert("n-queens cdcl is SAT", sat)
+	b.Assert("n-queens cdcl is SAT", result.Sat())
 
-	if model, ok := bdd2.FindModel(solution); ok {
-		queens_r := model.Variables(true)
-		queens := make([]Variable, 0, len(queens_r))
-		for _, q := range queens_r {
-			if _, ok := q.(*StringVariable); ok {
-				queens = append(queens, q)
+	queens_r := result.Model.Variables(true)
+	queens := make([]Variable, 0, len(queens_r))
+	for _, q := range queens_r {
+		if _, ok := q.(*StringVariable); ok {
+			queens = append(queens, q)
+		}
+	}
+	b.AssertInfo("there are n queens", len(queens) == n, queens)
+}
+
+func TestNQueensEnumerate(t *testing.T) {
+	b := bdd_test.Bench{T: t}
+
+	// number of solutions of the n-queens problem
+	solutions := map[int]int{4: 2, 5: 10, 6: 4, 7: 40, 8: 92}
+
+	for n, expected := range solutions {
+		cnf := algorithm.TransformTseitin(algorithm.NNF(makeNQueensExpression(n)))
+
+		// project onto the board, ignoring the auxiliary variables of the Tseitin transformation
+		board := make([]Variable, 0, n*n)
+		for _, v := range Variables(cnf) {
+			if _, ok := v.(*StringVariable); ok {
+				board = append(board, v.Variable())
 			}
 		}
-		b.AssertInfo("there are n queens", len(queens) == n, queens)
+
+		count := 0
+		algorithm.EnumerateModels(cnf, board, func(model Model) bool {
+			count++
+			return true
+		})
+
+		b.AssertInfo(fmt.Sprintf("%d-queens has %d solutions", n, expected), count == expected, count)
+
+		counted := algorithm.CountModels(cnf, board)
+		b.AssertInfo(fmt.Sprintf("%d-queens counts %d solutions", n, expected), counted.Int64() == int64(expected), counted)
 	}
 }
 
@@ -82,54 +110,98 @@ func makeNQueensExpression(n int) Expression {
 
 	var expr Expression = Cons(true)
 
-	// every row must have at least one queen
+	// every row and every column must have exactly one queen
 	for i := 0; i < n; i++ {
-		var disj Expression = Cons(false)
+		row, column := make([]Term, n), make([]Term, n)
 		for j := 0; j < n; j++ {
-			disj = Or(disj, field[i][j])
+			row[j], column[j] = field[i][j], field[j][i]
 		}
-		expr = And(expr, disj)
+		expr = And(expr, Exactly(1, row...), Exactly(1, column...))
 	}
 
-	// every column must have at least one queen
-	for i := 0; i < n; i++ {
-		var disj Expression = Cons(false)
-		for j := 0; j < n; j++ {
-			disj = Or(disj, field[j][i])
+	// no two queens on a single diagonal
+	for d := 1; d < 2*n-2; d++ {
+		var diagonal, antiDiagonal []Term
+		for i := 0; i < n; i++ {
+			if j := d - i; j >= 0 && j < n {
+				antiDiagonal = append(antiDiagonal, field[i][j])
+			}
+			if j := i + d - (n - 1); j >= 0 && j < n {
+				diagonal = append(diagonal, field[i][j])
+			}
 		}
-		expr = And(expr, disj)
+		expr = And(expr, AtMost(1, diagonal...), AtMost(1, antiDiagonal...))
 	}
 
-	// every row must have at most one queen
-	for i := 0; i < n; i++ {
-		for j1 := 1; j1 < n; j1++ {
-			for j2 := 0; j2 < j1; j2++ {
-				expr = And(expr, Or(Not(field[i][j1]), Not(field[i][j2])))
+	return expr
+}
+
+// makeNQueensCNF encodes the n-queens problem directly in CNF, with a pairwise at-most-one constraint for every row,
+// column and diagonal. Without auxiliary variables, the CNF suits local search.
+func makeNQueensCNF(n int) (CNF, [][]Variable) {
+	field := make([][]Variable, n)
+	for i := range field {
+		field[i] = make([]Variable, n)
+		for j := range field[i] {
+			field[i][j] = Var(fmt.Sprintf("p_%d_%d", i, j))
+		}
+	}
+
+	var cnf CNF
+	atMostOne := func(terms []Term) {
+		for i := range terms {
+			for j := i + 1; j < len(terms); j++ {
+				cnf = append(cnf, NClause{terms[i].Negate(), terms[j].Negate()})
 			}
 		}
 	}
 
-	// every column must have at most one queen
 	for i := 0; i < n; i++ {
-		for j1 := 1; j1 < n; j1++ {
-			for j2 := 0; j2 < j1; j2++ {
-				expr = And(expr, Or(Not(field[j1][i]), Not(field[j2][i])))
-			}
+		row, column := make(NClause, n), make([]Term, n)
+		for j := 0; j < n; j++ {
+			row[j], column[j] = field[i][j], field[j][i]
 		}
+		cnf = append(cnf, row)
+		atMostOne(row)
+		atMostOne(column)
 	}
 
-	// no two queens on a single diagonal
-	for i := 1; i < n*n; i++ {
-		i1, j1 := i/n, i%n
-		for j := 0; j < i; j++ {
-			i2, j2 := j/n, j%n
-			if i1-j1 == i2-j2 || i1+j1 == i2+j2 {
-				expr = And(expr, Or(Not(field[i1][j1]), Not(field[i2][j2])))
+	for d := 1; d < 2*n-2; d++ {
+		var diagonal, antiDiagonal []Term
+		for i := 0; i < n; i++ {
+			if j := d - i; j >= 0 && j < n {
+				antiDiagonal = append(antiDiagonal, field[i][j])
+			}
+			if j := i + d - (n - 1); j >= 0 && j < n {
+				diagonal = append(diagonal, field[i][j])
 			}
 		}
+		atMostOne(diagonal)
+		atMostOne(antiDiagonal)
 	}
 
-	return expr
+	return cnf, field
+}
+
+func TestNQueensLocalSearch(t *testing.T) {
+	const n = 50
+
+	b := bdd_test.Bench{T: t}
+
+	cnf, field := makeNQueensCNF(n)
+	result := algorithm.LocalSearch(cnf, algorithm.LocalSearchOptions{FlipBudget: 1000000, Seed: 1})
+
+	b.AssertInfo("n-queens local search is SAT", result.Sat(), result.Stats)
+
+	queens := 0
+	for i := range field {
+		for j := range field[i] {
+			if result.Model[field[i][j]] {
+				queens++
+			}
+		}
+	}
+	b.AssertInfo("there are n queens", queens == n, queens)
 }
 
 func benchmarkNQueens(n int, b *testing.B) {
@@ -149,3 +221,26 @@ func BenchmarkNQueens(b *testing.B) {
 		})
 	}
 }
+
+func benchmarkNQueensCDCL(n int, solve func(CNF) algorithm.Result, b *testing.B) {
+	cnf := algorithm.TransformTseitin(algorithm.NNF(makeNQueensExpression(n)))
+	test := bdd_test.Bench{T: b}
+
+	for i := 0; i < b.N; i++ {
+		test.Assert("n-queens is satisfiable", solve(cnf).Sat())
+	}
+}
+
+// BenchmarkNQueensCDCL compares the iterative CDCL solver to the recursive implementation.
+// The recursive implementation is only benchmarked for n = 4, since it does not finish within minutes for n = 5.
+func BenchmarkNQueensCDCL(b *testing.B) {
+	b.Run("RecursiveCDCL:4", func(b *testing.B) {
+		benchmarkNQueensCDCL(4, algorithm.RecursiveCDCL, b)
+	})
+
+	for i := 4; i <= 8; i++ {
+		b.Run(fmt.Sprintf("CDCL:%d", i), func(b *testing.B) {
+			benchmarkNQueensCDCL(i, algorithm.CDCL, b)
+		})
+	}
+}
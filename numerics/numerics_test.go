@@ -1,6 +1,7 @@
 package numerics
 
 import (
+	"context"
 	"fmt"
 	"github.com/timbeurskens/gobdd/algorithm"
 	"github.com/timbeurskens/gobdd/bdd_test"
@@ -90,6 +91,94 @@ func TestDivision(t *testing.T) {
 	}
 }
 
+func TestAddCDCL(t *testing.T) {
+	bench := bdd_test.Bench{T: t}
+
+	a, b, c := Variable(2), Constant(1, 2), Constant(3, 3)
+
+	// a + 1 = 3
+	expr := Add(a, b, c)
+
+	cnf := algorithm.TransformTseitin(algorithm.NNF(expr))
+	result := algorithm.CDCL(cnf)
+
+	bench.Assert("a + 1 = 3 is sat", result.Sat())
+
+	aResolv, err := a.Resolve(result.Model)
+	bench.AssertInfo("a is in the model", err == nil, err)
+	bench.AssertInfo("a = 2", aResolv == 2, aResolv)
+
+	cResolv, err := c.Resolve(result.Model)
+	bench.AssertInfo("constant numbers resolve to their value", err == nil && cResolv == 3, cResolv, err)
+}
+
+func TestPrimeDecompositionXor(t *testing.T) {
+	bench := bdd_test.Bench{T: t}
+
+	// 143 = 11 x 13
+	expr, a, b := makePrimeTest(143)
+
+	// the sums in the multiplication are kept as xor clauses, so the expression is not normalized
+	cnf, xors := algorithm.TransformTseitinWith(expr, algorithm.TseitinOptions{NativeXor: true})
+	result := algorithm.CDCLXor(cnf, xors)
+
+	bench.Assert("143 is a composed number", result.Sat())
+
+	aResolv, _ := a.Resolve(result.Model)
+	bResolv, _ := b.Resolve(result.Model)
+	bench.AssertInfo("a x b = 143", aResolv*bResolv == 143 && aResolv != 1 && bResolv != 1, aResolv, bResolv)
+
+	// 139 is prime
+	expr, _, _ = makePrimeTest(139)
+	cnf, xors = algorithm.TransformTseitinWith(expr, algorithm.TseitinOptions{NativeXor: true})
+
+	bench.Assert("139 is prime", !algorithm.CDCLXor(cnf, xors).Sat())
+}
+
+func TestPrimeDecompositionCubes(t *testing.T) {
+	bench := bdd_test.Bench{T: t}
+
+	// 899 = 29 x 31
+	expr, a, b := makePrimeTest(899)
+	cnf := algorithm.TransformTseitin(algorithm.NNF(expr))
+
+	// split on the bits of the factors
+	var factors []operators.Variable
+	for _, bit := range append(append(Number{}, a...), b...) {
+		factors = append(factors, bit.Variable())
+	}
+
+	cubes := algorithm.Cubes(cnf, algorithm.CubeOptions{Depth: 6, Variables: factors})
+	result := algorithm.CubeAndConquer(context.Background(), cnf, cubes, 4)
+
+	bench.Assert("899 is a composed number", result.Sat())
+
+	aResolv, _ := a.Resolve(result.Model)
+	bResolv, _ := b.Resolve(result.Model)
+	bench.AssertInfo("a x b = 899", aResolv*bResolv == 899 && aResolv != 1 && bResolv != 1, aResolv, bResolv)
+}
+
+func TestSumCDCL(t *testing.T) {
+	bench := bdd_test.Bench{T: t}
+
+	a, b := NamedVariable("a", 4), NamedVariable("b", 4)
+
+	// 3a + 2b = 23 and a > b
+	expr := operators.And(
+		SumAtLeast(23, []int{3, 2}, a, b),
+		SumAtMost(23, []int{3, 2}, a, b),
+		SumAtLeast(1, []int{1, -1}, a, b),
+	)
+
+	result := algorithm.CDCL(algorithm.TransformTseitin(algorithm.NNF(expr)))
+	bench.Assert("3a + 2b = 23 and a > b is sat", result.Sat())
+
+	aResolv, _ := a.Resolve(result.Model)
+	bResolv, _ := b.Resolve(result.Model)
+	bench.AssertInfo("3a + 2b = 23", 3*aResolv+2*bResolv == 23, aResolv, bResolv)
+	bench.AssertInfo("a > b", aResolv > bResolv, aResolv, bResolv)
+}
+
 func TestSqrt(t *testing.T) {
 	bench := bdd_test.Bench{T: t}
 
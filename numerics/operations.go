@@ -122,3 +122,31 @@ func Shift(a Number, n int) Number {
 
 	return res
 }
+
+// SumAtLeast constructs the linear constraint Σ coefficients[i]·numbers[i] ≥ k as a single pseudo-boolean constraint
+// over the bits of the numbers, which does not need the adders of Add
+func SumAtLeast(k int, coefficients []int, numbers ...Number) *operators.PseudoBoolean {
+	if len(coefficients) != len(numbers) {
+		panic("number of coefficients and numbers should match")
+	}
+
+	var weights []int
+	var bits []operators.Term
+	for i, n := range numbers {
+		for j := range n {
+			weights = append(weights, coefficients[i]<<j)
+			bits = append(bits, n[j])
+		}
+	}
+
+	return operators.SumAtLeast(k, weights, bits)
+}
+
+// SumAtMost constructs the linear constraint Σ coefficients[i]·numbers[i] ≤ k
+func SumAtMost(k int, coefficients []int, numbers ...Number) *operators.PseudoBoolean {
+	negated := make([]int, len(coefficients))
+	for i, c := range coefficients {
+		negated[i] = -c
+	}
+	return SumAtLeast(-k, negated, numbers...)
+}
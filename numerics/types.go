@@ -16,10 +16,16 @@ func (n Number) IsConstant() bool {
 	return true
 }
 
+// Resolve returns the value of n in a model, e.g. the model of a CDCL result or bdd.FindModel.
+// Constant bits do not need to be in the model.
 func (n Number) Resolve(model operators.Model) (uint, error) {
 	var result uint = 0
 	for i := range n {
-		if v, ok := n[i].(operators.Variable); !ok {
+		if c, ok := n[i].(operators.Constant); ok {
+			if c.Value() {
+				result |= 1 << i
+			}
+		} else if v, ok := n[i].(operators.Variable); !ok {
 			return 0, fmt.Errorf("could not cast %+v to type Variable", n[i])
 		} else if s, ok := model[v]; !ok {
 			return 0, fmt.Errorf("variable %+v could not be found in the model", v)
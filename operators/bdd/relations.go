@@ -77,3 +77,76 @@ func SubtreeSearch(root operators.Node, assignment operators.Model, search opera
 	// if the tree has nodes other than choice and constant, fail immediately
 	return false
 }
+
+// Implied returns the literals that are true in every model of subtree n, e.g. the options that are forced or
+// forbidden by the choices so far. A variable is implied if every path to the leaf true decides it the same way, so
+// variables that are skipped on one of these paths are free. If n is unsatisfiable, sat is false and implied is nil.
+func Implied(n operators.Node) (implied []operators.Term, sat bool) {
+	assignments, sat := impliedSearch(n, make(map[operators.Node]impliedResult))
+	if !sat {
+		return nil, false
+	}
+
+	implied = make([]operators.Term, len(assignments))
+	for i, a := range assignments {
+		if a.value {
+			implied[i] = a.variable
+		} else {
+			implied[i] = a.variable.Negate()
+		}
+	}
+
+	return implied, true
+}
+
+type impliedAssignment struct {
+	variable operators.Variable
+	value    bool
+}
+
+type impliedResult struct {
+	assignments []impliedAssignment
+	sat         bool
+}
+
+// impliedSearch returns the assignments that are shared by every path from root to the leaf true.
+// The result of every shared subtree is computed once.
+func impliedSearch(root operators.Node, cache map[operators.Node]impliedResult) ([]impliedAssignment, bool) {
+	if r, ok := cache[root]; ok {
+		return r.assignments, r.sat
+	}
+
+	var r impliedResult
+
+	switch node := root.(type) {
+	case *operators.Choice:
+		trueAssignments, trueSat := impliedSearch(node.True, cache)
+		falseAssignments, falseSat := impliedSearch(node.False, cache)
+
+		switch {
+		case trueSat && falseSat:
+			// only the assignments on both sides are implied
+			values := make(map[interface{}]bool, len(falseAssignments))
+			for _, a := range falseAssignments {
+				values[operators.VariableKey(a.variable)] = a.value
+			}
+			for _, a := range trueAssignments {
+				if value, ok := values[operators.VariableKey(a.variable)]; ok && value == a.value {
+					r.assignments = append(r.assignments, a)
+				}
+			}
+			r.sat = true
+		case trueSat:
+			r.assignments = append([]impliedAssignment{{node.Var, true}}, trueAssignments...)
+			r.sat = true
+		case falseSat:
+			r.assignments = append([]impliedAssignment{{node.Var, false}}, falseAssignments...)
+			r.sat = true
+		}
+	case operators.Constant:
+		r.sat = root.(operators.Constant).Value()
+	}
+
+	cache[root] = r
+	return r.assignments, r.sat
+}
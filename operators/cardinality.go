@@ -0,0 +1,98 @@
+package operators
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Cardinality is a constraint on the number of true terms: it is true iff at least Min and at most Max terms are true.
+// Contrary to the binary operators, a cardinality constraint is a leaf of the expression tree, such that the
+// transformations can encode it as a whole.
+type Cardinality struct {
+	Terms    []Term
+	Min, Max int
+}
+
+// AtMost returns the constraint that at most k of the terms are true
+func AtMost(k int, terms ...Term) *Cardinality {
+	return &Cardinality{Terms: terms, Min: 0, Max: k}
+}
+
+// AtLeast returns the constraint that at least k of the terms are true
+func AtLeast(k int, terms ...Term) *Cardinality {
+	return &Cardinality{Terms: terms, Min: k, Max: len(terms)}
+}
+
+// Exactly returns the constraint that exactly k of the terms are true
+func Exactly(k int, terms ...Term) *Cardinality {
+	return &Cardinality{Terms: terms, Min: k, Max: k}
+}
+
+// Complement returns the negation of the constraint, which holds iff fewer than Min or more than Max terms are true
+func (c *Cardinality) Complement() Expression {
+	var parts []Expression
+	if c.Min > 0 {
+		parts = append(parts, AtMost(c.Min-1, c.Terms...))
+	}
+	if c.Max < len(c.Terms) {
+		parts = append(parts, AtLeast(c.Max+1, c.Terms...))
+	}
+
+	switch len(parts) {
+	case 0:
+		return Cons(c.Min > c.Max)
+	case 1:
+		return parts[0]
+	}
+	return Or(parts...)
+}
+
+func (c *Cardinality) SetLeftChild(n Node) {
+	if n != nil {
+		panic("cardinality constraint has no left child")
+	}
+}
+
+func (c *Cardinality) SetRightChild(n Node) {
+	if n != nil {
+		panic("cardinality constraint has no right child")
+	}
+}
+
+// Normalize of a cardinality constraint is the constraint itself, it is encoded by the transformations
+func (c *Cardinality) Normalize() Expression {
+	return c
+}
+
+func (c *Cardinality) String() string {
+	terms := make([]string, len(c.Terms))
+	for i, t := range c.Terms {
+		if neg, ok := t.(*Negation); ok {
+			terms[i] = neg.String() + neg.Negate().String()
+		} else {
+			terms[i] = t.String()
+		}
+	}
+	return fmt.Sprintf("%d≤Σ(%s)≤%d", c.Min, strings.Join(terms, ", "), c.Max)
+}
+
+func (c *Cardinality) NodeEquivalent(n Node) bool {
+	other, ok := n.(*Cardinality)
+	if !ok || other.Min != c.Min || other.Max != c.Max || len(other.Terms) != len(c.Terms) {
+		return false
+	}
+	for i := range c.Terms {
+		if !c.Terms[i].TermEquivalent(other.Terms[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Cardinality) LeftChild() Node {
+	return nil
+}
+
+func (c *Cardinality) RightChild() Node {
+	return nil
+}
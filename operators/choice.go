@@ -8,7 +8,6 @@ type Choice struct {
 
 func (c *Choice) Normalize() Expression {
 	panic("normalization of choices is not supported")
-	return nil
 }
 
 func (c *Choice) SetLeftChild(n Node) {
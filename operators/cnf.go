@@ -53,14 +53,22 @@ func JoinCNF(a, b CNF) CNF {
 	return result
 }
 
+// Variables returns every variable in cnf once, in order of first occurrence.
+// Equivalent variables are found by their VariableKey, such that the cost is linear in the size of cnf.
 func Variables(cnf CNF) []Term {
 	result := make(NClause, 0)
+	seen := make(map[interface{}]bool)
 
 	for _, clause := range cnf {
 		terms := clause.Terms()
 		for _, term := range terms {
 			// term.Variable may return nil
-			if normal := term.Variable(); normal != nil && !result.HasTerm(normal) {
+			normal := term.Variable()
+			if normal == nil {
+				continue
+			}
+			if key := VariableKey(normal); !seen[key] {
+				seen[key] = true
 				result = append(result, normal)
 			}
 		}
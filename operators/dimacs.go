@@ -0,0 +1,195 @@
+package operators
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DIMACSError reports a syntax error in a DIMACS file
+type DIMACSError struct {
+	Line int
+	Err  string
+}
+
+func (e *DIMACSError) Error() string {
+	return fmt.Sprintf("dimacs: line %d: %s", e.Line, e.Err)
+}
+
+// ParseDIMACS reads a CNF in the DIMACS format.
+// Every DIMACS variable k is mapped to the integer variable IVar(k), where every occurrence of k shares the same
+// variable. Comment lines start with "c", and a line starting with "%" ends the file (as in the SATLIB benchmarks).
+func ParseDIMACS(r io.Reader) (CNF, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024*1024)
+
+	variables := make(map[int]Variable)
+	numVariables, numClauses := -1, -1
+	line := 0
+
+	var cnf CNF
+	var clause NClause
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" || text[0] == 'c' {
+			continue
+		}
+
+		if text[0] == '%' {
+			break
+		}
+
+		fields := strings.Fields(text)
+
+		if fields[0] == "p" {
+			if numVariables >= 0 {
+				return nil, &DIMACSError{line, "duplicate problem line"}
+			}
+			if len(fields) != 4 || fields[1] != "cnf" {
+				return nil, &DIMACSError{line, fmt.Sprintf("malformed problem line %q", text)}
+			}
+
+			var err1, err2 error
+			numVariables, err1 = strconv.Atoi(fields[2])
+			numClauses, err2 = strconv.Atoi(fields[3])
+			if err1 != nil || err2 != nil || numVariables < 0 || numClauses < 0 {
+				return nil, &DIMACSError{line, fmt.Sprintf("malformed problem line %q", text)}
+			}
+
+			cnf = make(CNF, 0, numClauses)
+			continue
+		}
+
+		if numVariables < 0 {
+			return nil, &DIMACSError{line, "clause before problem line"}
+		}
+
+		for _, field := range fields {
+			number, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, &DIMACSError{line, fmt.Sprintf("invalid literal %q", field)}
+			}
+
+			if number == 0 {
+				cnf = append(cnf, clause)
+				clause = nil
+				continue
+			}
+
+			key := number
+			if key < 0 {
+				key = -key
+			}
+
+			if key > numVariables {
+				return nil, &DIMACSError{line, fmt.Sprintf("variable %d exceeds the declared number of variables %d", key, numVariables)}
+			}
+
+			clause = append(clause, dimacsTerm(variables, number))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if numVariables < 0 {
+		return nil, &DIMACSError{line, "missing problem line"}
+	}
+
+	if clause != nil {
+		return nil, &DIMACSError{line, "last clause is not terminated by 0"}
+	}
+
+	if len(cnf) != numClauses {
+		return nil, &DIMACSError{line, fmt.Sprintf("expected %d clauses, found %d", numClauses, len(cnf))}
+	}
+
+	return cnf, nil
+}
+
+// dimacsTerm returns the term of a signed DIMACS variable number, where every variable is created only once
+func dimacsTerm(variables map[int]Variable, number int) Term {
+	key := number
+	if key < 0 {
+		key = -key
+	}
+
+	v, ok := variables[key]
+	if !ok {
+		v = IVar(key)
+		variables[key] = v
+	}
+
+	if number < 0 {
+		return v.Negate()
+	}
+	return v
+}
+
+// WriteDIMACS writes cnf in the DIMACS format, using the numbering of NewNumbering(cnf).
+// Clauses that contain the constant true are omitted, the constant false is removed from every clause.
+// The names of variables that do not keep their own number are written as comments.
+func WriteDIMACS(w io.Writer, cnf CNF) error {
+	numbering := NewNumbering(cnf)
+
+	clauses := make([][]int, 0, len(cnf))
+	for _, clause := range cnf {
+		if lits, ok := dimacsClause(numbering, clause); ok {
+			clauses = append(clauses, lits)
+		}
+	}
+
+	out := bufio.NewWriter(w)
+
+	for number := 1; number <= numbering.Max(); number++ {
+		v := numbering.Variable(number)
+		if i, ok := v.(*IntVariable); v == nil || (ok && int(*i) == number) {
+			continue
+		}
+		if _, err := fmt.Fprintf(out, "c %d %s\n", number, v); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(out, "p cnf %d %d\n", numbering.Max(), len(clauses)); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 0, 64)
+	for _, lits := range clauses {
+		buf = buf[:0]
+		for _, l := range lits {
+			buf = strconv.AppendInt(buf, int64(l), 10)
+			buf = append(buf, ' ')
+		}
+		buf = append(buf, '0', '\n')
+
+		if _, err := out.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	return out.Flush()
+}
+
+// dimacsClause converts a clause to signed variable numbers, ok is false if the clause is satisfied by a constant
+func dimacsClause(numbering *Numbering, clause CNFClause) (lits []int, ok bool) {
+	terms := clause.Terms()
+	lits = make([]int, 0, len(terms))
+	for _, t := range terms {
+		if c, isConst := t.(Constant); isConst {
+			if c.Value() {
+				return nil, false
+			}
+			continue
+		}
+		lits = append(lits, numbering.Literal(t))
+	}
+	return lits, true
+}
@@ -0,0 +1,85 @@
+package operators_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	. "github.com/timbeurskens/gobdd/operators"
+)
+
+func TestParseDIMACS(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	input := "c example\np cnf 4 3\n1 -2\n 3 0 -4 0\nc comment in between\n2 4 0\n%\n0\n"
+
+	cnf, err := ParseDIMACS(strings.NewReader(input))
+	be.AssertInfo("dimacs is parsed without errors", err == nil, err)
+	be.AssertInfo("cnf has 3 clauses", len(cnf) == 3, cnf)
+	be.Assert("first clause spans two lines", cnf[0].NumTerms() == 3)
+	be.Assert("first clause contains 1", cnf[0].HasTerm(IVar(1)))
+	be.Assert("first clause contains -2", cnf[0].HasTerm(IVar(2).Negate()))
+	be.Assert("second clause is -4", cnf[1].NumTerms() == 1 && cnf[1].HasTerm(IVar(4).Negate()))
+
+	// every occurrence of a variable shares the same pointer, such that models can be indexed by the variables
+	be.Assert("variables are shared", cnf[0].Terms()[1].Variable() == cnf[2].Terms()[0].Variable())
+}
+
+func TestParseDIMACSErrors(t *testing.T) {
+	inputs := map[string]int{
+		"1 2 0\n":                       1,
+		"c header\np cnf x 1\n":         2,
+		"p cnf 2 1\n\n1 a 0\n":          3,
+		"p cnf 2 1\n1 -3 0\n":           2,
+		"p cnf 2 2\n1 0\n2 0\n1 0\n":    4,
+		"p cnf 2 1\n1 2\n":              2,
+		"p cnf 2 1\np cnf 2 1\n1 2 0\n": 2,
+	}
+
+	for input, line := range inputs {
+		be := bdd_test.Bench{T: t}
+
+		_, err := ParseDIMACS(strings.NewReader(input))
+
+		var dimacsErr *DIMACSError
+		be.AssertInfo("error is reported on the right line", errors.As(err, &dimacsErr) && dimacsErr.Line == line, input, err)
+	}
+}
+
+func TestDIMACSRoundTrip(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	input := "p cnf 7 3\n7 -3 0\n1 0\n-5 2 3 0\n"
+
+	cnf, err := ParseDIMACS(strings.NewReader(input))
+	be.Assert("dimacs is parsed without errors", err == nil)
+
+	var out bytes.Buffer
+	err = WriteDIMACS(&out, cnf)
+	be.Assert("dimacs is written without errors", err == nil)
+	be.AssertInfo("numbering is preserved", out.String() == input, out.String())
+}
+
+func TestWriteDIMACS(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, x := Var("a"), Var("b"), IVar(2)
+
+	cnf := CNF{
+		NClause{a, b.Negate()},
+		NClause{x, Cons(false), a.Negate()},
+		NClause{b, Cons(true)},
+		b,
+	}
+
+	var out bytes.Buffer
+	err := WriteDIMACS(&out, cnf)
+	be.Assert("dimacs is written without errors", err == nil)
+
+	expected := "c 3 a\nc 4 b\np cnf 4 3\n3 -4 0\n2 -3 0\n4 0\n"
+	be.AssertInfo("integer variables keep their number, constants are removed", out.String() == expected, out.String())
+
+	parsed, err := ParseDIMACS(&out)
+	be.AssertInfo("written dimacs can be parsed", err == nil && len(parsed) == 3, err)
+}
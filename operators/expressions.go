@@ -26,20 +26,22 @@ func Biimplies(a, b Expression) Expression {
 	return &Biimplication{a, b}
 }
 
+// And returns the conjunction of expr, which is a flat n-ary conjunction for more than two operands
 func And(expr ...Expression) Expression {
 	if len(expr) == 1 {
 		return expr[0]
 	} else if len(expr) > 2 {
-		return And(expr[0], And(expr[1:]...))
+		return &NConjunction{append([]Expression(nil), expr...)}
 	}
 	return &Conjunction{expr[0], expr[1]}
 }
 
+// Or returns the disjunction of expr, which is a flat n-ary disjunction for more than two operands
 func Or(expr ...Expression) Expression {
 	if len(expr) == 1 {
 		return expr[0]
 	} else if len(expr) > 2 {
-		return Or(expr[0], Or(expr[1:]...))
+		return &NDisjunction{append([]Expression(nil), expr...)}
 	}
 	return &Disjunction{expr[0], expr[1]}
 }
@@ -0,0 +1,241 @@
+package operators
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Factory builds expressions with hash-consing: structurally identical expressions built by the same factory are a
+// single node, such that shared subexpressions are never copied.
+// Cardinality and pseudo-Boolean constraints are only shared by their pointer.
+type Factory struct {
+	// canonical maps every node seen by the factory to its canonical node
+	canonical map[Node]Expression
+	nodes     map[factoryKey]Expression
+	ids       map[Expression]int
+	normal    map[Expression]Expression
+}
+
+// factoryKey identifies a node by its type and the identifiers of its children.
+// Leafs are identified by their name.
+type factoryKey struct {
+	kind                reflect.Type
+	left, right, choice int
+	name                string
+}
+
+// NewFactory creates a factory without nodes
+func NewFactory() *Factory {
+	return &Factory{
+		canonical: make(map[Node]Expression),
+		nodes:     make(map[factoryKey]Expression),
+		ids:       make(map[Expression]int),
+		normal:    make(map[Expression]Expression),
+	}
+}
+
+// Len returns the number of distinct nodes in the factory
+func (f *Factory) Len() int {
+	return len(f.ids)
+}
+
+// Intern returns the canonical node of e. Every subexpression of e is interned, memoized by its pointer, such that
+// sharing in e is preserved and structurally identical subexpressions become a single node.
+func (f *Factory) Intern(e Expression) Expression {
+	if e == nil {
+		return nil
+	}
+	if c, ok := f.canonical[e]; ok {
+		return c
+	}
+
+	var result Expression
+	switch e.(type) {
+	case Variable, Constant:
+		result = f.node(factoryKey{kind: reflect.TypeOf(e), name: e.String()}, func() Expression { return e })
+	case *Cardinality, *PseudoBoolean:
+		result = f.node(factoryKey{kind: reflect.TypeOf(e), left: len(f.ids) + 1}, func() Expression { return e })
+	case *Negation:
+		result = f.negation(f.Intern(e.RightChild()))
+	case *Choice:
+		choice := e.(*Choice)
+		v := f.Intern(choice.Var).(Variable)
+		l, r := f.Intern(choice.True), f.Intern(choice.False)
+		key := factoryKey{kind: reflect.TypeOf(e), left: f.ids[l], right: f.ids[r], choice: f.ids[v]}
+		result = f.node(key, func() Expression { return JoinByChoice(v, l, r) })
+	case *NConjunction:
+		result = f.nary((*NConjunction)(nil), e.(*NConjunction).Operands)
+	case *NDisjunction:
+		result = f.nary((*NDisjunction)(nil), e.(*NDisjunction).Operands)
+	case Operator:
+		result = f.binary(e.(Operator), e.LeftChild(), e.RightChild())
+	default:
+		panic("unrecognized expression type in factory")
+	}
+
+	f.canonical[e] = result
+	return result
+}
+
+// node returns the node with the given key, or adds the node created by build
+func (f *Factory) node(key factoryKey, build func() Expression) Expression {
+	if n, ok := f.nodes[key]; ok {
+		return n
+	}
+	n := build()
+	f.nodes[key] = n
+	f.ids[n] = len(f.ids) + 1
+	f.canonical[n] = n
+	return n
+}
+
+// binary returns the canonical node of the operator op applied to a and b
+func (f *Factory) binary(op Operator, a, b Expression) Expression {
+	a, b = f.Intern(a), f.Intern(b)
+	key := factoryKey{kind: reflect.TypeOf(op), left: f.ids[a], right: f.ids[b]}
+	return f.node(key, func() Expression { return op.Join(a, b) })
+}
+
+// nary returns the canonical n-ary conjunction or disjunction of the operands, identified by the list of their
+// identifiers
+func (f *Factory) nary(op Operator, operands []Expression) Expression {
+	canonical := make([]Expression, len(operands))
+	ids := make([]int, len(operands))
+	for i, o := range operands {
+		canonical[i] = f.Intern(o)
+		ids[i] = f.ids[canonical[i]]
+	}
+
+	key := factoryKey{kind: reflect.TypeOf(op), name: fmt.Sprint(ids)}
+	return f.node(key, func() Expression {
+		if _, ok := op.(*NConjunction); ok {
+			return &NConjunction{canonical}
+		}
+		return &NDisjunction{canonical}
+	})
+}
+
+// negation returns the canonical negation of the canonical node e
+func (f *Factory) negation(e Expression) Expression {
+	key := factoryKey{kind: reflect.TypeOf((*Negation)(nil)), right: f.ids[e]}
+	return f.node(key, func() Expression { return &Negation{e} })
+}
+
+// Var returns the canonical variable with the given name
+func (f *Factory) Var(name string) Variable {
+	return f.Intern(Var(name)).(Variable)
+}
+
+// IVar returns the canonical integer variable i
+func (f *Factory) IVar(i int) Variable {
+	return f.Intern(IVar(i)).(Variable)
+}
+
+// Cons returns the canonical constant b
+func (f *Factory) Cons(b bool) Constant {
+	return f.Intern(Cons(b)).(Constant)
+}
+
+// Not is the negation of e, without double negations
+func (f *Factory) Not(e Expression) Expression {
+	e = f.Intern(e)
+	if neg, ok := e.(*Negation); ok {
+		return neg.RightChild()
+	} else if c, ok := e.(Constant); ok {
+		return f.Intern(c.Negate())
+	}
+	return f.negation(e)
+}
+
+// Implies returns a -> b
+func (f *Factory) Implies(a, b Expression) Expression {
+	return f.binary((*Implication)(nil), a, b)
+}
+
+// Biimplies returns a <-> b
+func (f *Factory) Biimplies(a, b Expression) Expression {
+	return f.binary((*Biimplication)(nil), a, b)
+}
+
+// And returns the conjunction of expr, which is a flat n-ary conjunction for more than two operands.
+// The conjunction of no operands is true.
+func (f *Factory) And(expr ...Expression) Expression {
+	if len(expr) == 0 {
+		return f.Cons(true)
+	} else if len(expr) == 1 {
+		return f.Intern(expr[0])
+	} else if len(expr) > 2 {
+		return f.nary((*NConjunction)(nil), expr)
+	}
+	return f.binary((*Conjunction)(nil), expr[0], expr[1])
+}
+
+// Or returns the disjunction of expr, which is a flat n-ary disjunction for more than two operands.
+// The disjunction of no operands is false.
+func (f *Factory) Or(expr ...Expression) Expression {
+	if len(expr) == 0 {
+		return f.Cons(false)
+	} else if len(expr) == 1 {
+		return f.Intern(expr[0])
+	} else if len(expr) > 2 {
+		return f.nary((*NDisjunction)(nil), expr)
+	}
+	return f.binary((*Disjunction)(nil), expr[0], expr[1])
+}
+
+// Xor returns the exclusive disjunction of expr, as a right-leaning chain
+func (f *Factory) Xor(expr ...Expression) Expression {
+	if len(expr) == 1 {
+		return f.Intern(expr[0])
+	} else if len(expr) > 2 {
+		return f.Xor(expr[0], f.Xor(expr[1:]...))
+	}
+	return f.binary((*ExclusiveDisjunction)(nil), expr[0], expr[1])
+}
+
+// Normalize is the normalization of e, which only contains conjunctions, disjunctions and negations.
+// Every subexpression is normalized once, such that the operands of bi-implications and exclusive disjunctions
+// are shared instead of copied.
+func (f *Factory) Normalize(e Expression) Expression {
+	e = f.Intern(e)
+	if n, ok := f.normal[e]; ok {
+		return n
+	}
+
+	var result Expression
+	switch e.(type) {
+	case Variable, Constant, *Cardinality, *PseudoBoolean:
+		result = e
+	case *Negation:
+		result = f.negation(f.Normalize(e.RightChild()))
+	case *Conjunction:
+		result = f.And(f.Normalize(e.LeftChild()), f.Normalize(e.RightChild()))
+	case *Disjunction:
+		result = f.Or(f.Normalize(e.LeftChild()), f.Normalize(e.RightChild()))
+	case *NConjunction:
+		result = f.And(f.normalizeOperands(e.(*NConjunction).Operands)...)
+	case *NDisjunction:
+		result = f.Or(f.normalizeOperands(e.(*NDisjunction).Operands)...)
+	case *Implication:
+		result = f.Or(f.Not(f.Normalize(e.LeftChild())), f.Normalize(e.RightChild()))
+	case *Biimplication:
+		left, right := f.Normalize(e.LeftChild()), f.Normalize(e.RightChild())
+		result = f.And(f.Or(f.Not(left), right), f.Or(f.Not(right), left))
+	case *ExclusiveDisjunction:
+		left, right := f.Normalize(e.LeftChild()), f.Normalize(e.RightChild())
+		result = f.And(f.Or(left, right), f.Not(f.And(left, right)))
+	default:
+		result = f.Intern(e.Normalize())
+	}
+
+	f.normal[e] = result
+	return result
+}
+
+func (f *Factory) normalizeOperands(operands []Expression) []Expression {
+	result := make([]Expression, len(operands))
+	for i, o := range operands {
+		result[i] = f.Normalize(o)
+	}
+	return result
+}
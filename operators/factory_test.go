@@ -0,0 +1,42 @@
+package operators_test
+
+import (
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	. "github.com/timbeurskens/gobdd/operators"
+)
+
+func TestFactorySharing(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	f := NewFactory()
+
+	a, b := f.Var("a"), f.Var("b")
+	be.Assert("variables with the same name are one node", f.Var("a") == a)
+	be.Assert("identical expressions are one node", f.Xor(a, f.Not(b)) == f.Xor(a, f.Not(b)))
+	be.Assert("different operators are different nodes", f.And(a, b) != f.Or(a, b))
+	be.Assert("operands are ordered", f.And(a, b) != f.And(b, a))
+	be.Assert("double negations are removed", f.Not(f.Not(a)) == a)
+
+	// expressions built without the factory are interned by their structure
+	be.Assert("foreign expressions are interned", f.Intern(And(Var("a"), Not(Var("b")))) == f.And(a, f.Not(b)))
+	be.Assert("constants are interned", f.Intern(Cons(true)) == f.Cons(true))
+}
+
+func TestFactoryNormalize(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	f := NewFactory()
+
+	// a chain of n exclusive disjunctions normalizes to an expression tree of exponential size
+	vars := make([]Expression, 24)
+	for i := range vars {
+		vars[i] = IVar(i)
+	}
+	chain := f.Xor(vars...)
+
+	before := f.Len()
+	normal := f.Normalize(chain)
+	be.AssertInfo("normalization adds a linear number of nodes", f.Len()-before <= 6*len(vars), f.Len()-before)
+	be.AssertInfo("the normalized expression is shared", Size(normal) <= 6*len(vars), Size(normal))
+	be.Assert("normalization is memoized", f.Normalize(chain) == normal)
+}
@@ -0,0 +1,166 @@
+package operators
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseICNF reads an incremental CNF in the iCNF format, as written by cube-and-conquer tools: a "p inccnf" problem
+// line, followed by clauses and by cubes, which are lines that start with "a". Every cube is a list of assumptions.
+// Variables are mapped to integer variables as in ParseDIMACS, and errors are reported as a DIMACSError.
+func ParseICNF(r io.Reader) (cnf CNF, cubes [][]Term, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024*1024)
+
+	variables := make(map[int]Variable)
+	header := false
+	line := 0
+
+	var clause NClause
+	var cube []Term
+	isCube := false
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" || text[0] == 'c' {
+			continue
+		}
+
+		fields := strings.Fields(text)
+
+		if fields[0] == "p" {
+			if header {
+				return nil, nil, &DIMACSError{line, "duplicate problem line"}
+			}
+			if len(fields) != 2 || fields[1] != "inccnf" {
+				return nil, nil, &DIMACSError{line, fmt.Sprintf("malformed problem line %q", text)}
+			}
+			header = true
+			continue
+		}
+
+		if !header {
+			return nil, nil, &DIMACSError{line, "clause before problem line"}
+		}
+
+		if fields[0] == "a" {
+			if clause != nil || isCube {
+				return nil, nil, &DIMACSError{line, "cube inside a clause or cube"}
+			}
+			isCube, cube = true, []Term{}
+			fields = fields[1:]
+		}
+
+		for _, field := range fields {
+			number, convErr := strconv.Atoi(field)
+			if convErr != nil {
+				return nil, nil, &DIMACSError{line, fmt.Sprintf("invalid literal %q", field)}
+			}
+
+			switch {
+			case number == 0 && isCube:
+				cubes = append(cubes, cube)
+				cube, isCube = nil, false
+			case number == 0:
+				cnf = append(cnf, clause)
+				clause = nil
+			case isCube:
+				cube = append(cube, dimacsTerm(variables, number))
+			default:
+				clause = append(clause, dimacsTerm(variables, number))
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if !header {
+		return nil, nil, &DIMACSError{line, "missing problem line"}
+	}
+
+	if clause != nil || isCube {
+		return nil, nil, &DIMACSError{line, "last clause or cube is not terminated by 0"}
+	}
+
+	return cnf, cubes, nil
+}
+
+// WriteICNF writes cnf and a list of cubes in the iCNF format, such that every cube can be solved as a set of
+// assumptions by an incremental solver. Variables are numbered as in WriteDIMACS, over the clauses followed by the
+// cubes. Cubes that contain the constant false are omitted, the constant true is removed from every cube.
+func WriteICNF(w io.Writer, cnf CNF, cubes [][]Term) error {
+	all := make(CNF, 0, len(cnf)+len(cubes))
+	all = append(all, cnf...)
+	for _, cube := range cubes {
+		all = append(all, NClause(cube))
+	}
+
+	numbering := NewNumbering(all)
+
+	out := bufio.NewWriter(w)
+
+	for number := 1; number <= numbering.Max(); number++ {
+		v := numbering.Variable(number)
+		if i, ok := v.(*IntVariable); v == nil || (ok && int(*i) == number) {
+			continue
+		}
+		if _, err := fmt.Fprintf(out, "c %d %s\n", number, v); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintln(out, "p inccnf"); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 0, 64)
+	for _, clause := range cnf {
+		lits, ok := dimacsClause(numbering, clause)
+		if !ok {
+			continue
+		}
+
+		buf = buf[:0]
+		for _, l := range lits {
+			buf = strconv.AppendInt(buf, int64(l), 10)
+			buf = append(buf, ' ')
+		}
+		buf = append(buf, '0', '\n')
+
+		if _, err := out.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	for _, cube := range cubes {
+		// a cube is a conjunction: the constant true is neutral, the constant false refutes the cube
+		negated := make(NClause, len(cube))
+		for i, t := range cube {
+			negated[i] = t.Negate()
+		}
+		lits, ok := dimacsClause(numbering, negated)
+		if !ok {
+			continue
+		}
+
+		buf = append(buf[:0], 'a', ' ')
+		for _, l := range lits {
+			buf = strconv.AppendInt(buf, int64(-l), 10)
+			buf = append(buf, ' ')
+		}
+		buf = append(buf, '0', '\n')
+
+		if _, err := out.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	return out.Flush()
+}
@@ -0,0 +1,82 @@
+package operators_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	. "github.com/timbeurskens/gobdd/operators"
+)
+
+func TestParseICNF(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	input := "c cubes\np inccnf\n1 -2 0\n2 3\n 0\na 1 2 0\na -1 0\na 0\n"
+
+	cnf, cubes, err := ParseICNF(strings.NewReader(input))
+	be.AssertInfo("icnf is parsed without errors", err == nil, err)
+	be.AssertInfo("clauses are parsed", len(cnf) == 2, cnf)
+	be.AssertInfo("cubes are parsed", len(cubes) == 3, cubes)
+	be.Assert("second clause spans two lines", cnf[1].NumTerms() == 2 && cnf[1].HasTerm(IVar(3)))
+	be.Assert("first cube contains 1 and 2", len(cubes[0]) == 2 && cubes[0][1].Variable() == cnf[1].Terms()[0].Variable())
+	be.Assert("second cube contains -1", len(cubes[1]) == 1 && cubes[1][0].Variable() == cnf[0].Terms()[0].Variable())
+	be.Assert("third cube is empty", len(cubes[2]) == 0)
+}
+
+func TestParseICNFErrors(t *testing.T) {
+	inputs := map[string]int{
+		"p cnf 2 1\n1 2 0\n":           1,
+		"1 2 0\np inccnf\n":            1,
+		"p inccnf\np inccnf\n":         2,
+		"p inccnf\n1 x 0\n":            2,
+		"p inccnf\na 1 x 0\n":          2,
+		"p inccnf\n1 2\na 1 0\n":       3,
+		"p inccnf\na 1\na 2 0\n":       3,
+		"p inccnf\n1 0\na 1 2\n":       3,
+		"c comment\n\np inccnf\n1 2\n": 4,
+	}
+
+	for input, line := range inputs {
+		be := bdd_test.Bench{T: t}
+
+		_, _, err := ParseICNF(strings.NewReader(input))
+
+		var dimacsErr *DIMACSError
+		be.AssertInfo("error is reported on the right line", errors.As(err, &dimacsErr) && dimacsErr.Line == line, input, err)
+	}
+}
+
+func TestICNFRoundTrip(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	input := "p inccnf\n4 -3 0\n1 2 0\na -1 3 0\na 1 0\n"
+
+	cnf, cubes, err := ParseICNF(strings.NewReader(input))
+	be.Assert("icnf is parsed without errors", err == nil)
+
+	var out bytes.Buffer
+	err = WriteICNF(&out, cnf, cubes)
+	be.Assert("icnf is written without errors", err == nil)
+	be.AssertInfo("clauses are written before cubes, with numbering preserved", out.String() == input, out.String())
+}
+
+func TestWriteICNF(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c := Var("a"), Var("b"), Var("c")
+
+	cnf := CNF{NClause{a, b}}
+	cubes := [][]Term{
+		{a.Negate(), Cons(true)},
+		{b, Cons(false)},
+		{c},
+	}
+
+	var out bytes.Buffer
+	err := WriteICNF(&out, cnf, cubes)
+	be.Assert("icnf is written without errors", err == nil)
+
+	expected := "c 1 a\nc 2 b\nc 3 c\np inccnf\n1 2 0\na -1 0\na 3 0\n"
+	be.AssertInfo("refuted cubes are omitted, true is removed from cubes", out.String() == expected, out.String())
+}
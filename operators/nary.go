@@ -0,0 +1,149 @@
+package operators
+
+// NConjunction is a flat conjunction of any number of operands.
+// As a node, it is the binary conjunction of its first operand (left) and the conjunction of the remaining operands
+// (right), such that transformations that only know binary operators remain correct.
+type NConjunction struct {
+	Operands []Expression
+}
+
+// NDisjunction is a flat disjunction of any number of operands.
+// As a node, it is the binary disjunction of its first operand (left) and the disjunction of the remaining operands
+// (right), such that transformations that only know binary operators remain correct.
+type NDisjunction struct {
+	Operands []Expression
+}
+
+// restOperands returns the remaining operands as a single expression, joined by join if there is more than one
+func restOperands(operands []Expression, join func([]Expression) Expression) Node {
+	switch len(operands) {
+	case 0, 1:
+		return nil
+	case 2:
+		return operands[1]
+	default:
+		return join(operands[1:])
+	}
+}
+
+func (c *NConjunction) SetLeftChild(n Node) {
+	// the operands are copied, since they can be shared with the right child of another node
+	if len(c.Operands) == 0 {
+		c.Operands = []Expression{n}
+	} else {
+		c.Operands = append([]Expression{n}, c.Operands[1:]...)
+	}
+}
+
+func (c *NConjunction) SetRightChild(n Node) {
+	if len(c.Operands) == 0 {
+		c.Operands = append([]Expression(nil), flattenConjunction(n)...)
+	} else {
+		c.Operands = append(c.Operands[:1:1], flattenConjunction(n)...)
+	}
+}
+
+func (c *NConjunction) Normalize() Expression {
+	operands := make([]Expression, len(c.Operands))
+	for i, o := range c.Operands {
+		operands[i] = o.Normalize()
+	}
+	return &NConjunction{operands}
+}
+
+func (c *NConjunction) String() string {
+	return "∧"
+}
+
+func (c *NConjunction) Join(a, b Expression) Operator {
+	return &NConjunction{append([]Expression{a}, flattenConjunction(b)...)}
+}
+
+func (c *NConjunction) ConstEval(a, b Constant) Constant {
+	return Cons(a.Value() && b.Value())
+}
+
+func (c *NConjunction) NodeEquivalent(n Node) bool {
+	other, ok := n.(*NConjunction)
+	return ok && len(other.Operands) == len(c.Operands)
+}
+
+func (c *NConjunction) LeftChild() Node {
+	if len(c.Operands) == 0 {
+		return nil
+	}
+	return c.Operands[0]
+}
+
+func (c *NConjunction) RightChild() Node {
+	return restOperands(c.Operands, func(rest []Expression) Expression { return &NConjunction{rest} })
+}
+
+// flattenConjunction returns the operands of e if e is an n-ary conjunction, or e itself otherwise
+func flattenConjunction(e Expression) []Expression {
+	if c, ok := e.(*NConjunction); ok {
+		return c.Operands
+	}
+	return []Expression{e}
+}
+
+func (d *NDisjunction) SetLeftChild(n Node) {
+	// the operands are copied, since they can be shared with the right child of another node
+	if len(d.Operands) == 0 {
+		d.Operands = []Expression{n}
+	} else {
+		d.Operands = append([]Expression{n}, d.Operands[1:]...)
+	}
+}
+
+func (d *NDisjunction) SetRightChild(n Node) {
+	if len(d.Operands) == 0 {
+		d.Operands = append([]Expression(nil), flattenDisjunction(n)...)
+	} else {
+		d.Operands = append(d.Operands[:1:1], flattenDisjunction(n)...)
+	}
+}
+
+func (d *NDisjunction) Normalize() Expression {
+	operands := make([]Expression, len(d.Operands))
+	for i, o := range d.Operands {
+		operands[i] = o.Normalize()
+	}
+	return &NDisjunction{operands}
+}
+
+func (d *NDisjunction) String() string {
+	return "∨"
+}
+
+func (d *NDisjunction) Join(a, b Expression) Operator {
+	return &NDisjunction{append([]Expression{a}, flattenDisjunction(b)...)}
+}
+
+func (d *NDisjunction) ConstEval(a, b Constant) Constant {
+	return Cons(a.Value() || b.Value())
+}
+
+func (d *NDisjunction) NodeEquivalent(n Node) bool {
+	other, ok := n.(*NDisjunction)
+	return ok && len(other.Operands) == len(d.Operands)
+}
+
+func (d *NDisjunction) LeftChild() Node {
+	if len(d.Operands) == 0 {
+		return nil
+	}
+	return d.Operands[0]
+}
+
+func (d *NDisjunction) RightChild() Node {
+	return restOperands(d.Operands, func(rest []Expression) Expression { return &NDisjunction{rest} })
+}
+
+// flattenDisjunction returns the operands of e if e is an n-ary disjunction, or e itself otherwise
+func flattenDisjunction(e Expression) []Expression {
+	if d, ok := e.(*NDisjunction); ok {
+		return d.Operands
+	}
+	return []Expression{e}
+}
@@ -0,0 +1,34 @@
+package operators_test
+
+import (
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	. "github.com/timbeurskens/gobdd/operators"
+)
+
+func TestNaryChildren(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c := Var("a"), Var("b"), Var("c")
+
+	e := And(a, b, c).(*NConjunction)
+	be.Assert("the left child is the first operand", e.LeftChild() == a)
+
+	rest, ok := e.RightChild().(*NConjunction)
+	be.Assert("the right child is the conjunction of the remaining operands", ok && len(rest.Operands) == 2)
+	be.Assert("the right child of two operands is the last operand", rest.RightChild() == c)
+
+	joined := e.Join(e.LeftChild(), e.RightChild()).(*NConjunction)
+	be.AssertInfo("joining the children restores the operands", len(joined.Operands) == 3 && joined.Operands[2] == c, joined.Operands)
+
+	// a clone built from the children has the same operands
+	clone := &NDisjunction{}
+	clone.SetLeftChild(a)
+	clone.SetRightChild(Or(b, c, a))
+	be.AssertInfo("setting the children flattens the operands", len(clone.Operands) == 4, clone.Operands)
+
+	// the right child of a node without operands becomes all of its operands
+	empty := &NConjunction{}
+	empty.SetRightChild(And(a, b, c))
+	be.AssertInfo("setting the right child of an empty node flattens the operands", len(empty.Operands) == 3, empty.Operands)
+}
@@ -0,0 +1,112 @@
+package operators
+
+// VariableKey returns a comparable key for v, such that equivalent variables share the same key.
+// Contrary to the variables themselves, the keys can be used to index maps by value.
+func VariableKey(v Variable) interface{} {
+	switch v := v.(type) {
+	case *StringVariable:
+		return *v
+	case *IntVariable:
+		return *v
+	case *AuxVariable:
+		return *v
+	}
+	return v
+}
+
+// Numbering assigns a unique positive number to variables, as used in the DIMACS format.
+// Positive integer variables keep their own number, such that DIMACS files can be read and written without changing
+// the numbering. Every other variable, including the auxiliary variables of IncVar, is numbered densely in order of
+// first occurrence, after the largest integer variable.
+type Numbering struct {
+	numbers   map[interface{}]int
+	variables map[int]Variable
+	max       int
+}
+
+// NewNumbering creates a numbering for every variable in cnf
+func NewNumbering(cnf CNF) *Numbering {
+	n := &Numbering{
+		numbers:   make(map[interface{}]int),
+		variables: make(map[int]Variable),
+	}
+
+	variables := Variables(cnf)
+
+	// integer variables first, such that other variables cannot claim their number
+	for _, term := range variables {
+		if i, ok := term.(*IntVariable); ok && *i > 0 {
+			n.Number(i)
+		}
+	}
+
+	for _, term := range variables {
+		n.Number(term.Variable())
+	}
+
+	return n
+}
+
+// Number returns the number of v. Unknown variables are numbered on the fly.
+func (n *Numbering) Number(v Variable) int {
+	key := VariableKey(v)
+	if number, ok := n.numbers[key]; ok {
+		return number
+	}
+
+	number := n.max + 1
+	if i, ok := v.(*IntVariable); ok && *i > 0 {
+		if _, taken := n.variables[int(*i)]; !taken {
+			number = int(*i)
+		}
+	}
+
+	n.numbers[key] = number
+	n.variables[number] = v
+	if number > n.max {
+		n.max = number
+	}
+
+	return number
+}
+
+// Fresh returns an unused number that is not associated with any variable
+func (n *Numbering) Fresh() int {
+	n.max++
+	n.variables[n.max] = nil
+	return n.max
+}
+
+// Literal returns the signed number of a term: negative iff the term is a negation.
+// Literal panics if t is a constant.
+func (n *Numbering) Literal(t Term) int {
+	negative := false
+	for {
+		if neg, ok := t.(*Negation); ok {
+			negative = !negative
+			t = neg.Negate()
+		} else {
+			break
+		}
+	}
+
+	v, ok := t.(Variable)
+	if !ok {
+		panic("numbering: a constant has no number")
+	}
+
+	if negative {
+		return -n.Number(v)
+	}
+	return n.Number(v)
+}
+
+// Variable returns the variable with the given number, or nil if the number is not associated with a variable
+func (n *Numbering) Variable(number int) Variable {
+	return n.variables[number]
+}
+
+// Max returns the largest number in use
+func (n *Numbering) Max() int {
+	return n.max
+}
@@ -0,0 +1,203 @@
+package operators
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// OPBError reports a syntax error in an OPB file
+type OPBError struct {
+	Line int
+	Err  string
+}
+
+func (e *OPBError) Error() string {
+	return fmt.Sprintf("opb: line %d: %s", e.Line, e.Err)
+}
+
+// Objective is a linear function over terms that should be minimized
+type Objective struct {
+	Coefficients []int
+	Terms        []Term
+}
+
+// Value returns the value of the objective in a model
+func (o *Objective) Value(model Model) int {
+	values := make(map[interface{}]bool, len(model))
+	for v, value := range model {
+		values[VariableKey(v)] = value
+	}
+
+	result := 0
+	for i, t := range o.Terms {
+		_, negative := t.(*Negation)
+		if values[VariableKey(t.Variable())] != negative {
+			result += o.Coefficients[i]
+		}
+	}
+	return result
+}
+
+// SoftClauses converts the objective into soft clauses for MaxSAT, such that the value of the objective is offset
+// plus the total weight of the falsified soft clauses. A term with a positive coefficient a becomes the soft clause
+// ¬t with weight a, a term with a negative coefficient a becomes the soft clause t with weight -a and adds a to offset.
+func (o *Objective) SoftClauses() (soft []SoftClause, offset int) {
+	for i, t := range o.Terms {
+		switch a := o.Coefficients[i]; {
+		case a > 0:
+			soft = append(soft, SoftClause{Clause: t.Negate(), Weight: a})
+		case a < 0:
+			soft = append(soft, SoftClause{Clause: t, Weight: -a})
+			offset += a
+		}
+	}
+	return
+}
+
+// ParseOPB reads a linear pseudo-Boolean problem in the OPB format, as used by the pseudo-Boolean competitions.
+// Every variable xk is mapped to the integer variable IVar(k), a literal ~xk is its negation.
+// Equality constraints are returned as two inequalities. If the file has no "min:" line, the objective is nil.
+// Comment lines start with "*", and the "#variable=" and "#constraint=" counts in the first comment are checked.
+func ParseOPB(r io.Reader) (constraints []*PseudoBoolean, objective *Objective, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024*1024)
+
+	variables := make(map[int]Variable)
+	numVariables, numConstraints, found := -1, -1, 0
+	line := 0
+
+	// the statement that is being read: the objective, or a constraint with the relation and bound after the terms
+	var coefficients []int
+	var terms []Term
+	var relation string
+	var bound *int
+	isObjective, started := false, false
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" {
+			continue
+		}
+
+		if text[0] == '*' {
+			if line == 1 {
+				numVariables, numConstraints = opbHeader(text)
+			}
+			continue
+		}
+
+		fields := strings.Fields(strings.ReplaceAll(text, ";", " ; "))
+
+		for _, field := range fields {
+			switch {
+			case field == "min:":
+				if started || objective != nil || len(constraints) > 0 {
+					return nil, nil, &OPBError{line, "unexpected objective"}
+				}
+				isObjective, started = true, true
+
+			case field == ";":
+				if len(coefficients) != len(terms) {
+					return nil, nil, &OPBError{line, "coefficient without literal"}
+				}
+				if isObjective {
+					objective = &Objective{Coefficients: coefficients, Terms: terms}
+				} else if bound == nil {
+					return nil, nil, &OPBError{line, "constraint without relation and bound"}
+				} else {
+					found++
+					if relation == ">=" || relation == "=" {
+						constraints = append(constraints, SumAtLeast(*bound, coefficients, terms))
+					}
+					if relation == "<=" || relation == "=" {
+						constraints = append(constraints, SumAtMost(*bound, coefficients, terms))
+					}
+				}
+				coefficients, terms, relation, bound = nil, nil, "", nil
+				isObjective, started = false, false
+
+			case field == ">=" || field == "=" || field == "<=":
+				if isObjective || relation != "" {
+					return nil, nil, &OPBError{line, fmt.Sprintf("unexpected relation %q", field)}
+				}
+				if len(coefficients) != len(terms) {
+					return nil, nil, &OPBError{line, "coefficient without literal"}
+				}
+				relation, started = field, true
+
+			case bound != nil:
+				return nil, nil, &OPBError{line, fmt.Sprintf("expected ; instead of %q", field)}
+
+			case relation != "":
+				k, convErr := strconv.Atoi(field)
+				if convErr != nil {
+					return nil, nil, &OPBError{line, fmt.Sprintf("invalid bound %q", field)}
+				}
+				bound = &k
+
+			case len(coefficients) == len(terms):
+				if strings.HasPrefix(field, "x") || strings.HasPrefix(field, "~") {
+					return nil, nil, &OPBError{line, fmt.Sprintf("non-linear term with literal %q is not supported", field)}
+				}
+				a, convErr := strconv.Atoi(field)
+				if convErr != nil {
+					return nil, nil, &OPBError{line, fmt.Sprintf("invalid coefficient %q", field)}
+				}
+				coefficients, started = append(coefficients, a), true
+
+			default:
+				negative := strings.HasPrefix(field, "~")
+				number, convErr := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(field, "~"), "x"))
+				if convErr != nil || number <= 0 || !strings.HasPrefix(strings.TrimPrefix(field, "~"), "x") {
+					return nil, nil, &OPBError{line, fmt.Sprintf("invalid literal %q", field)}
+				}
+				if numVariables >= 0 && number > numVariables {
+					return nil, nil, &OPBError{line, fmt.Sprintf("variable %d exceeds the declared number of variables %d", number, numVariables)}
+				}
+				if negative {
+					number = -number
+				}
+				terms = append(terms, dimacsTerm(variables, number))
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if started {
+		return nil, nil, &OPBError{line, "last statement is not terminated by ;"}
+	}
+
+	if numConstraints >= 0 && found != numConstraints {
+		return nil, nil, &OPBError{line, fmt.Sprintf("expected %d constraints, found %d", numConstraints, found)}
+	}
+
+	return constraints, objective, nil
+}
+
+// opbHeader reads the number of variables and constraints from the first comment, or returns -1 if they are missing
+func opbHeader(text string) (numVariables, numConstraints int) {
+	numVariables, numConstraints = -1, -1
+
+	fields := strings.Fields(text)
+	for i := 0; i+1 < len(fields); i++ {
+		n, err := strconv.Atoi(fields[i+1])
+		if err != nil {
+			continue
+		}
+		switch fields[i] {
+		case "#variable=":
+			numVariables = n
+		case "#constraint=":
+			numConstraints = n
+		}
+	}
+	return
+}
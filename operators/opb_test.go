@@ -0,0 +1,76 @@
+package operators_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	. "github.com/timbeurskens/gobdd/operators"
+)
+
+func TestParseOPB(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	input := "* #variable= 3 #constraint= 3\n* comment\nmin: +2 x1 -3 ~x2 ;\n+1 x1 +1 x2 >= 1 ;\n" +
+		"-2 x1\n +3 ~x3 = 1;\n+1 x2 +1 x3 <= 1 ;\n"
+
+	constraints, objective, err := ParseOPB(strings.NewReader(input))
+	be.AssertInfo("opb is parsed without errors", err == nil, err)
+	be.AssertInfo("equalities are split into two constraints", len(constraints) == 4, constraints)
+
+	be.Assert("objective has two terms", objective != nil && len(objective.Terms) == 2)
+	be.AssertInfo("objective has coefficients 2 and -3", objective.Coefficients[0] == 2 && objective.Coefficients[1] == -3, objective.Coefficients)
+	be.Assert("objective contains ~x2", objective.Terms[1].TermEquivalent(IVar(2).Negate()))
+
+	be.AssertInfo("first constraint is x1 + x2 ≥ 1", constraints[0].NodeEquivalent(SumAtLeast(1, []int{1, 1}, []Term{IVar(1), IVar(2)})), constraints[0])
+	be.AssertInfo("equality spans two lines", constraints[1].NodeEquivalent(SumAtLeast(1, []int{-2, 3}, []Term{IVar(1), IVar(3).Negate()})), constraints[1])
+	be.AssertInfo("at most is stored with negated coefficients", constraints[3].NodeEquivalent(SumAtLeast(-1, []int{-1, -1}, []Term{IVar(2), IVar(3)})), constraints[3])
+
+	// every occurrence of a variable shares the same pointer, such that models can be indexed by the variables
+	be.Assert("variables are shared", constraints[0].Terms[0] == constraints[1].Terms[0])
+}
+
+func TestParseOPBErrors(t *testing.T) {
+	inputs := map[string]int{
+		"+1 x1 +1 x2 >= 1\n":           1,
+		"+1 x1 >= 1 ;\nmin: +1 x1 ;\n": 2,
+		"+1 x1 x2 >= 1 ;\n":            1,
+		"+1 x1 +1 >= 1 ;\n":            1,
+		"+1 y1 >= 1 ;\n":               1,
+		"+a x1 >= 1 ;\n":               1,
+		"+1 x1 >= b ;\n":               1,
+		"+1 x1\n >= 1 2 ;\n":           2,
+		"min: +1 x1 >= 1 ;\n":          1,
+		"+1 x1 ;\n":                    1,
+		"* #variable= 2 #constraint= 1\n+1 x3 >= 1 ;\n": 2,
+		"* #variable= 2 #constraint= 2\n+1 x1 >= 1 ;\n": 2,
+	}
+
+	for input, line := range inputs {
+		be := bdd_test.Bench{T: t}
+
+		_, _, err := ParseOPB(strings.NewReader(input))
+
+		var opbErr *OPBError
+		be.AssertInfo("error is reported on the right line", errors.As(err, &opbErr) && opbErr.Line == line, input, err)
+	}
+}
+
+func TestObjective(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b, c := Var("a"), Var("b"), Var("c")
+
+	objective := &Objective{Coefficients: []int{2, -3, 4}, Terms: []Term{a, b.Negate(), c}}
+
+	model := Model{Var("a"): true, Var("b"): false, Var("c"): false}
+	be.AssertInfo("objective is evaluated by value", objective.Value(model) == 2-3, objective.Value(model))
+
+	soft, offset := objective.SoftClauses()
+	be.AssertInfo("every term becomes a soft clause", len(soft) == 3, soft)
+	be.AssertInfo("positive coefficients penalize true terms", soft[0].Weight == 2 && soft[0].Clause.HasTerm(a.Negate()), soft[0])
+	be.AssertInfo("negative coefficients penalize false terms", soft[1].Weight == 3 && soft[1].Clause.HasTerm(b.Negate()), soft[1])
+
+	// in the model, only the soft clause ¬a is falsified
+	be.AssertInfo("value is offset plus the weight of the falsified soft clauses", offset+soft[0].Weight == objective.Value(model), offset)
+}
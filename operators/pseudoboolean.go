@@ -0,0 +1,92 @@
+package operators
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PseudoBoolean is a linear constraint over terms: it is true iff Σ Coefficients[i]·Terms[i] ≥ Bound,
+// where a term counts 1 if it is true and 0 otherwise. Coefficients can be negative.
+// Like a cardinality constraint, a pseudo-Boolean constraint is a leaf of the expression tree.
+type PseudoBoolean struct {
+	Coefficients []int
+	Terms        []Term
+	Bound        int
+}
+
+// SumAtLeast returns the constraint Σ coefficients[i]·terms[i] ≥ k
+func SumAtLeast(k int, coefficients []int, terms []Term) *PseudoBoolean {
+	if len(coefficients) != len(terms) {
+		panic("pseudo-boolean constraint: number of coefficients and terms differ")
+	}
+	return &PseudoBoolean{Coefficients: coefficients, Terms: terms, Bound: k}
+}
+
+// SumAtMost returns the constraint Σ coefficients[i]·terms[i] ≤ k, which is stored as Σ -coefficients[i]·terms[i] ≥ -k
+func SumAtMost(k int, coefficients []int, terms []Term) *PseudoBoolean {
+	negated := make([]int, len(coefficients))
+	for i, a := range coefficients {
+		negated[i] = -a
+	}
+	return SumAtLeast(-k, negated, terms)
+}
+
+// SumEquals returns the constraint Σ coefficients[i]·terms[i] = k, as the conjunction of two inequalities
+func SumEquals(k int, coefficients []int, terms []Term) Expression {
+	return And(SumAtLeast(k, coefficients, terms), SumAtMost(k, coefficients, terms))
+}
+
+// Complement returns the negation of the constraint: Σ coefficients[i]·terms[i] ≤ Bound-1
+func (p *PseudoBoolean) Complement() Expression {
+	return SumAtMost(p.Bound-1, p.Coefficients, p.Terms)
+}
+
+func (p *PseudoBoolean) SetLeftChild(n Node) {
+	if n != nil {
+		panic("pseudo-boolean constraint has no left child")
+	}
+}
+
+func (p *PseudoBoolean) SetRightChild(n Node) {
+	if n != nil {
+		panic("pseudo-boolean constraint has no right child")
+	}
+}
+
+// Normalize of a pseudo-boolean constraint is the constraint itself, it is encoded by the transformations
+func (p *PseudoBoolean) Normalize() Expression {
+	return p
+}
+
+func (p *PseudoBoolean) String() string {
+	terms := make([]string, len(p.Terms))
+	for i, t := range p.Terms {
+		if neg, ok := t.(*Negation); ok {
+			terms[i] = fmt.Sprintf("%d·%s%s", p.Coefficients[i], neg, neg.Negate())
+		} else {
+			terms[i] = fmt.Sprintf("%d·%s", p.Coefficients[i], t)
+		}
+	}
+	return fmt.Sprintf("Σ(%s)≥%d", strings.Join(terms, ", "), p.Bound)
+}
+
+func (p *PseudoBoolean) NodeEquivalent(n Node) bool {
+	other, ok := n.(*PseudoBoolean)
+	if !ok || other.Bound != p.Bound || len(other.Terms) != len(p.Terms) {
+		return false
+	}
+	for i := range p.Terms {
+		if p.Coefficients[i] != other.Coefficients[i] || !p.Terms[i].TermEquivalent(other.Terms[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *PseudoBoolean) LeftChild() Node {
+	return nil
+}
+
+func (p *PseudoBoolean) RightChild() Node {
+	return nil
+}
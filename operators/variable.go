@@ -146,3 +146,78 @@ func (c *IntVariable) Leq(variable Variable) bool {
 	other, ok := variable.(*IntVariable)
 	return ok && *c <= *other
 }
+
+// AuxVariable is an auxiliary variable, introduced by a transformation such as the Tseitin transformation.
+// Auxiliary variables are created by IncVar only, and never collide with integer or string variables.
+type AuxVariable int
+
+func (c *AuxVariable) Variable() Variable {
+	return c
+}
+
+func (c *AuxVariable) SetLeftChild(n Node) {
+	if n != nil {
+		panic("auxiliary variable has no left child")
+	}
+}
+
+func (c *AuxVariable) SetRightChild(n Node) {
+	if n != nil {
+		panic("auxiliary variable has no right child")
+	}
+}
+
+func (c *AuxVariable) Normalize() Expression {
+	return c
+}
+
+func (c *AuxVariable) Terms() []Term {
+	return []Term{c}
+}
+
+func (c *AuxVariable) HasTerm(term Term) bool {
+	return c.TermEquivalent(term)
+}
+
+func (c *AuxVariable) Exclude(term Term) CNFClause {
+	if c.HasTerm(term) {
+		return nil
+	} else {
+		return c
+	}
+}
+
+func (c *AuxVariable) NumTerms() int {
+	return 1
+}
+
+func (c *AuxVariable) Negate() Term {
+	return &Negation{c}
+}
+
+func (c *AuxVariable) TermEquivalent(t Term) bool {
+	other, ok := t.(*AuxVariable)
+	return ok && c.NodeEquivalent(other)
+}
+
+func (c *AuxVariable) NodeEquivalent(n Node) bool {
+	other, ok := n.(*AuxVariable)
+	return ok && *other == *c
+}
+
+func (c *AuxVariable) LeftChild() Node {
+	return nil
+}
+
+func (c *AuxVariable) RightChild() Node {
+	return nil
+}
+
+func (c *AuxVariable) String() string {
+	return fmt.Sprintf("_%d", *c)
+}
+
+func (c *AuxVariable) Leq(variable Variable) bool {
+	other, ok := variable.(*AuxVariable)
+	return ok && *c <= *other
+}
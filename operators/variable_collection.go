@@ -1,15 +1,16 @@
 package operators
 
-const IncVarMask int = 573829
+import "sync/atomic"
 
 var (
-	varCount = 0
+	varCount int64 = 0
 )
 
+// IncVar returns a fresh auxiliary variable. It is safe for concurrent use: every call returns a different variable,
+// such that transformations in different goroutines never share auxiliary variables.
 func IncVar() Term {
-	result := IVar(varCount ^ IncVarMask)
-	varCount += 1
-	return result
+	v := AuxVariable(atomic.AddInt64(&varCount, 1))
+	return &v
 }
 
 func IncVarCollection(n int) []Term {
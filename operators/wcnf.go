@@ -0,0 +1,185 @@
+package operators
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SoftClause is a clause that should preferably be satisfied.
+// Falsifying the clause costs Weight.
+type SoftClause struct {
+	Clause CNFClause
+	Weight int
+}
+
+// ParseWCNF reads a weighted partial MaxSAT instance in the WCNF format.
+// Both the classic format, with a "p wcnf" problem line where every clause with a weight of at least top is hard,
+// and the newer format without a problem line, where hard clauses are prefixed by "h", are supported.
+// As in ParseDIMACS, every variable k is mapped to the integer variable IVar(k).
+func ParseWCNF(r io.Reader) (hard CNF, soft []SoftClause, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024*1024)
+
+	variables := make(map[int]Variable)
+	numVariables, numClauses, top := -1, -1, -1
+	line := 0
+
+	var clause NClause
+	started, isHard, weight := false, false, 0
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" || text[0] == 'c' {
+			continue
+		}
+
+		fields := strings.Fields(text)
+
+		if fields[0] == "p" {
+			if numVariables >= 0 || len(hard)+len(soft) > 0 || started {
+				return nil, nil, &DIMACSError{line, "unexpected problem line"}
+			}
+			if (len(fields) != 4 && len(fields) != 5) || fields[1] != "wcnf" {
+				return nil, nil, &DIMACSError{line, fmt.Sprintf("malformed problem line %q", text)}
+			}
+
+			var err1, err2, err3 error
+			numVariables, err1 = strconv.Atoi(fields[2])
+			numClauses, err2 = strconv.Atoi(fields[3])
+			if len(fields) == 5 {
+				top, err3 = strconv.Atoi(fields[4])
+			}
+			if err1 != nil || err2 != nil || err3 != nil || numVariables < 0 || numClauses < 0 {
+				return nil, nil, &DIMACSError{line, fmt.Sprintf("malformed problem line %q", text)}
+			}
+			continue
+		}
+
+		for _, field := range fields {
+			if !started {
+				// every clause starts with its weight
+				started = true
+				if field == "h" && numVariables < 0 {
+					isHard = true
+					continue
+				}
+
+				var convErr error
+				weight, convErr = strconv.Atoi(field)
+				if convErr != nil || weight < 1 {
+					return nil, nil, &DIMACSError{line, fmt.Sprintf("invalid weight %q", field)}
+				}
+				isHard = top > 0 && weight >= top
+				continue
+			}
+
+			number, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, nil, &DIMACSError{line, fmt.Sprintf("invalid literal %q", field)}
+			}
+
+			if number == 0 {
+				if isHard {
+					hard = append(hard, clause)
+				} else {
+					soft = append(soft, SoftClause{Clause: clause, Weight: weight})
+				}
+				clause, started = nil, false
+				continue
+			}
+
+			if numVariables >= 0 && (number > numVariables || -number > numVariables) {
+				return nil, nil, &DIMACSError{line, fmt.Sprintf("variable %d exceeds the declared number of variables %d", number, numVariables)}
+			}
+
+			clause = append(clause, dimacsTerm(variables, number))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if started {
+		return nil, nil, &DIMACSError{line, "last clause is not terminated by 0"}
+	}
+
+	if numClauses >= 0 && len(hard)+len(soft) != numClauses {
+		return nil, nil, &DIMACSError{line, fmt.Sprintf("expected %d clauses, found %d", numClauses, len(hard)+len(soft))}
+	}
+
+	return hard, soft, nil
+}
+
+// WriteWCNF writes a weighted partial MaxSAT instance in the classic WCNF format, with a "p wcnf" problem line.
+// Hard clauses get the weight top, which exceeds the sum of the weights of the soft clauses.
+// Variables are numbered as in WriteDIMACS, over the hard clauses followed by the soft clauses.
+func WriteWCNF(w io.Writer, hard CNF, soft []SoftClause) error {
+	all := make(CNF, 0, len(hard)+len(soft))
+	all = append(all, hard...)
+	for _, s := range soft {
+		all = append(all, s.Clause)
+	}
+
+	numbering := NewNumbering(all)
+
+	type weightedClause struct {
+		weight int
+		lits   []int
+	}
+
+	top := 1
+	for _, s := range soft {
+		top += s.Weight
+	}
+
+	clauses := make([]weightedClause, 0, len(all))
+	for _, clause := range hard {
+		if lits, ok := dimacsClause(numbering, clause); ok {
+			clauses = append(clauses, weightedClause{top, lits})
+		}
+	}
+	for _, s := range soft {
+		if lits, ok := dimacsClause(numbering, s.Clause); ok && s.Weight > 0 {
+			clauses = append(clauses, weightedClause{s.Weight, lits})
+		}
+	}
+
+	out := bufio.NewWriter(w)
+
+	for number := 1; number <= numbering.Max(); number++ {
+		v := numbering.Variable(number)
+		if i, ok := v.(*IntVariable); v == nil || (ok && int(*i) == number) {
+			continue
+		}
+		if _, err := fmt.Fprintf(out, "c %d %s\n", number, v); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(out, "p wcnf %d %d %d\n", numbering.Max(), len(clauses), top); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 0, 64)
+	for _, clause := range clauses {
+		buf = strconv.AppendInt(buf[:0], int64(clause.weight), 10)
+		buf = append(buf, ' ')
+		for _, l := range clause.lits {
+			buf = strconv.AppendInt(buf, int64(l), 10)
+			buf = append(buf, ' ')
+		}
+		buf = append(buf, '0', '\n')
+
+		if _, err := out.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	return out.Flush()
+}
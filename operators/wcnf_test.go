@@ -0,0 +1,95 @@
+package operators_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/timbeurskens/gobdd/bdd_test"
+	. "github.com/timbeurskens/gobdd/operators"
+)
+
+func TestParseWCNF(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	input := "c classic\np wcnf 3 4 10\n10 1 -2 0\n3 2 0\n10 3\n 0\n5 -1 -3 0\n"
+
+	hard, soft, err := ParseWCNF(strings.NewReader(input))
+	be.AssertInfo("wcnf is parsed without errors", err == nil, err)
+	be.AssertInfo("clauses with weight top are hard", len(hard) == 2, hard)
+	be.AssertInfo("other clauses are soft", len(soft) == 2, soft)
+	be.Assert("second hard clause spans two lines", hard[1].NumTerms() == 1 && hard[1].HasTerm(IVar(3)))
+	be.AssertInfo("soft clauses keep their weight", soft[0].Weight == 3 && soft[1].Weight == 5, soft)
+	be.Assert("soft clause contains -3", soft[1].Clause.HasTerm(IVar(3).Negate()))
+}
+
+func TestParseWCNFHard(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	input := "c new format\nh 1 -2 0\n4 2 0\nh 3 0\n1 -1 -3 0\n"
+
+	hard, soft, err := ParseWCNF(strings.NewReader(input))
+	be.AssertInfo("wcnf is parsed without errors", err == nil, err)
+	be.AssertInfo("clauses prefixed by h are hard", len(hard) == 2 && len(soft) == 2, hard, soft)
+	be.Assert("variables are shared", hard[0].Terms()[1].Variable() == soft[0].Clause.Terms()[0].Variable())
+}
+
+func TestParseWCNFErrors(t *testing.T) {
+	inputs := map[string]int{
+		"p cnf 2 1\n1 2 0\n":           1,
+		"p wcnf 2 1 10\n0 1 0\n":       2,
+		"p wcnf 2 1 10\nh 1 0\n":       2,
+		"p wcnf 2 1\n1 -3 0\n":         2,
+		"h 1 2\n":                      1,
+		"1 2 0\np wcnf 2 1\n":          2,
+		"p wcnf 2 2 10\n\n10 1 0\n":    3,
+		"p wcnf 2 1 10\n10 x 0\n":      2,
+		"h 1 0\nc comment\n-1 1 0\n":   3,
+		"p wcnf 2 x 10\n10 1 0\n":      1,
+		"p wcnf 2 1 10\n10 1 0\n1 0\n": 3,
+	}
+
+	for input, line := range inputs {
+		be := bdd_test.Bench{T: t}
+
+		_, _, err := ParseWCNF(strings.NewReader(input))
+
+		var dimacsErr *DIMACSError
+		be.AssertInfo("error is reported on the right line", errors.As(err, &dimacsErr) && dimacsErr.Line == line, input, err)
+	}
+}
+
+func TestWCNFRoundTrip(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+
+	input := "p wcnf 4 3 8\n8 4 -3 0\n2 1 0\n5 -2 3 0\n"
+
+	hard, soft, err := ParseWCNF(strings.NewReader(input))
+	be.Assert("wcnf is parsed without errors", err == nil)
+
+	var out bytes.Buffer
+	err = WriteWCNF(&out, hard, soft)
+	be.Assert("wcnf is written without errors", err == nil)
+	be.AssertInfo("hard clauses are written first, with numbering preserved", out.String() == "p wcnf 4 3 8\n8 4 -3 0\n2 1 0\n5 -2 3 0\n", out.String())
+}
+
+func TestWriteWCNF(t *testing.T) {
+	be := bdd_test.Bench{T: t}
+	a, b := Var("a"), Var("b")
+
+	hard := CNF{NClause{a, b}}
+	soft := []SoftClause{
+		{Clause: a.Negate(), Weight: 3},
+		{Clause: NClause{b.Negate(), Cons(true)}, Weight: 4},
+		{Clause: b.Negate(), Weight: 0},
+		{Clause: b.Negate(), Weight: 2},
+	}
+
+	var out bytes.Buffer
+	err := WriteWCNF(&out, hard, soft)
+	be.Assert("wcnf is written without errors", err == nil)
+
+	expected := "c 1 a\nc 2 b\np wcnf 2 3 10\n10 1 2 0\n3 -1 0\n2 -2 0\n"
+	be.AssertInfo("satisfied and weightless soft clauses are omitted", out.String() == expected, out.String())
+}
@@ -276,7 +276,7 @@ func makePrimeTest(prime int) (Expression, []Term, []Term) {
 }
 
 func TestIsPrimeCDCL(t *testing.T) {
-	t.Skip("Test is intractable")
+	bench := bdd_test.Bench{T: t}
 
 	prime := 4
 	expr, a, b := makePrimeTest(prime)
@@ -285,23 +285,34 @@ func TestIsPrimeCDCL(t *testing.T) {
 	nnf := algorithm.NNF(expr)
 	cnf := algorithm.TransformTseitin(nnf)
 
-	for i, clause := range cnf {
-		terms := clause.Terms()
+	t.Log("variable count: ", len(Variables(cnf)))
 
-		exprs := make([]Expression, len(terms))
+	result := algorithm.CDCL(cnf)
 
-		for i := range terms {
-			exprs[i] = terms[i]
-		}
+	bench.Assert(fmt.Sprintf("%d is not prime", prime), result.Sat())
 
-		t.Log(i, PrintExpressiontree(Or(exprs...)))
-	}
+	aResolv, bResolv := resolveNumber(a, result.Model), resolveNumber(b, result.Model)
+	bench.AssertInfo("decomposition is correct", aResolv*bResolv == prime, aResolv, bResolv)
+}
 
-	t.Log("variable count: ", len(Variables(cnf)))
+func benchmarkPrimeCDCL(prime int, solve func(CNF) algorithm.Result, b *testing.B) {
+	expr, _, _ := makePrimeTest(prime)
+	cnf := algorithm.TransformTseitin(algorithm.NNF(expr))
+	test := bdd_test.Bench{T: b}
 
-	sat, m := algorithm.CDCL(cnf)
+	for i := 0; i < b.N; i++ {
+		test.Assert(fmt.Sprintf("%d is not prime", prime), solve(cnf).Sat())
+	}
+}
 
-	t.Log(sat, m, a, b)
+// BenchmarkIsPrimeCDCL benchmarks the iterative CDCL solver.
+// The recursive implementation is not included: it does not finish within minutes, even for 4.
+func BenchmarkIsPrimeCDCL(b *testing.B) {
+	for _, prime := range []int{4, 6, 15} {
+		b.Run(fmt.Sprintf("CDCL:%d", prime), func(b *testing.B) {
+			benchmarkPrimeCDCL(prime, algorithm.CDCL, b)
+		})
+	}
 }
 
 func TestIsPrimeBDD(t *testing.T) {